@@ -0,0 +1,248 @@
+// Package batch groups items submitted one at a time into batches run on a gopool.Pool.
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/syncgroup"
+)
+
+// ErrClosed is the error of items added after Close.
+var ErrClosed = errors.New("batch: batcher is closed")
+
+// Errors is an error returned by a batch function to fail some items of a batch only.
+// It holds one error, possibly nil, for each item of the batch, in order.
+type Errors []error
+
+// Error returns the non-nil errors joined.
+func (e Errors) Error() string {
+	if err := errors.Join(e...); err != nil {
+		return err.Error()
+	}
+
+	return "batch: no item failed"
+}
+
+// Unwrap returns the errors of the items, so that errors.Is and errors.As see them.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// Result is the outcome of an item added to a Batcher.
+type Result struct {
+	done chan struct{} // done is closed once the batch of the item has run.
+	err  error         // err is the error of the item.
+}
+
+// newResult creates the Result of an item.
+func newResult() *Result {
+	return &Result{done: make(chan struct{}), err: nil}
+}
+
+// complete sets the error of the item and releases the waiters.
+func (r *Result) complete(err error) {
+	r.err = err
+	close(r.done)
+}
+
+// Done returns a channel that is closed once the batch of the item has run.
+func (r *Result) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait waits for the batch of the item to run and returns the error of the item.
+// If ctx is done first, it returns the context error.
+func (r *Result) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Batcher accumulates items into batches, flushed when they reach the maximum size
+// or after the maximum wait, and runs each batch with a batch function on a pool.
+type Batcher[T any] struct {
+	ctx      context.Context                            // ctx is the context handed to the batch function.
+	pool     *gopool.Pool                               // pool runs the batches.
+	run      func(ctx context.Context, items []T) error // run is the batch function.
+	maxSize  int                                        // maxSize is the number of items that triggers a flush.
+	maxWait  time.Duration                              // maxWait is the time after which a partial batch is flushed.
+	drained  *sync.Cond                                 // drained is signalled when a batch has run.
+	mu       sync.Mutex                                 // mu protects the fields below it.
+	items    []T                                        // items stores the items of the pending batch.
+	results  []*Result                                  // results stores the results of the pending items.
+	timer    *time.Timer                                // timer flushes the pending batch after maxWait.
+	gen      uint64                                     // gen identifies the pending batch, for the timer.
+	inflight int                                        // inflight is the number of flushed batches that have not run yet.
+	closed   bool                                       // closed indicates if the batcher has been closed.
+}
+
+// New creates a new Batcher running batches with run. If run returns Errors with one error
+// per item, each item gets its own error; otherwise all items of the batch get the error.
+// A panic in run fails the items of the batch with a *syncgroup.PanicError.
+func New[T any](run func(ctx context.Context, items []T) error, options ...Option) *Batcher[T] {
+	cfg := newConfig(options)
+
+	b := &Batcher[T]{ //nolint: exhaustruct
+		ctx:     cfg.ctx,
+		pool:    gopool.New(gopool.Context(cfg.ctx), gopool.MaxGoroutines(cfg.maxConcurrency)),
+		run:     run,
+		maxSize: cfg.maxSize,
+		maxWait: cfg.maxWait,
+	}
+	b.drained = sync.NewCond(&b.mu)
+
+	return b
+}
+
+// Add adds an item to the pending batch and returns its Result. The batch is flushed
+// if the item fills it. An item added after Close fails with ErrClosed.
+func (b *Batcher[T]) Add(item T) *Result {
+	result := newResult()
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		result.complete(ErrClosed)
+
+		return result
+	}
+
+	b.items = append(b.items, item)
+	b.results = append(b.results, result)
+
+	var items []T
+	var results []*Result
+	switch {
+	case len(b.items) >= b.maxSize:
+		items, results = b.take()
+	case len(b.items) == 1 && b.maxWait > 0:
+		gen := b.gen
+		b.timer = time.AfterFunc(b.maxWait, func() { b.flushTimer(gen) })
+	}
+	b.mu.Unlock()
+
+	b.submit(items, results)
+
+	return result
+}
+
+// Flush flushes the pending batch and waits for all flushed batches to run.
+func (b *Batcher[T]) Flush() {
+	b.mu.Lock()
+	items, results := b.take()
+	b.mu.Unlock()
+
+	b.submit(items, results)
+	b.drain()
+}
+
+// Close stops accepting items, flushes the pending batch and waits for all batches to run.
+func (b *Batcher[T]) Close() {
+	b.mu.Lock()
+	b.closed = true
+	items, results := b.take()
+	b.mu.Unlock()
+
+	b.submit(items, results)
+	b.drain()
+	b.pool.Wait()
+}
+
+// Stats returns a snapshot of the pool running the batches.
+func (b *Batcher[T]) Stats() gopool.Stats {
+	return b.pool.Stats()
+}
+
+// take removes the pending batch and accounts for it as in flight. It must be called with mu held.
+func (b *Batcher[T]) take() ([]T, []*Result) {
+	if len(b.items) == 0 {
+		return nil, nil
+	}
+
+	items, results := b.items, b.results
+	b.items, b.results = nil, nil
+	b.gen++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.inflight++
+
+	return items, results
+}
+
+// flushTimer flushes the pending batch if it is still the batch gen the timer was started for.
+func (b *Batcher[T]) flushTimer(gen uint64) {
+	b.mu.Lock()
+	if b.gen != gen {
+		b.mu.Unlock()
+
+		return // The batch has already been flushed.
+	}
+	items, results := b.take()
+	b.mu.Unlock()
+
+	b.submit(items, results)
+}
+
+// submit runs a batch on the pool, failing its items if the pool does not accept it.
+func (b *Batcher[T]) submit(items []T, results []*Result) {
+	if len(items) == 0 {
+		return
+	}
+
+	err := b.pool.GoContext(b.ctx, func() error {
+		b.finish(results, b.call(items))
+
+		return nil
+	})
+	if err != nil {
+		b.finish(results, err)
+	}
+}
+
+// call runs the batch function, returning a panic as a *syncgroup.PanicError.
+func (b *Batcher[T]) call(items []T) (err error) {
+	defer func() {
+		if pc := recover(); pc != nil {
+			err = syncgroup.NewPanicError(pc)
+		}
+	}()
+
+	return b.run(b.ctx, items)
+}
+
+// finish passes the error of a batch to the results of its items.
+func (b *Batcher[T]) finish(results []*Result, err error) {
+	var itemErrs Errors
+	perItem := errors.As(err, &itemErrs) && len(itemErrs) == len(results)
+	for i, result := range results {
+		if perItem {
+			result.complete(itemErrs[i])
+		} else {
+			result.complete(err)
+		}
+	}
+
+	b.mu.Lock()
+	b.inflight--
+	b.drained.Broadcast()
+	b.mu.Unlock()
+}
+
+// drain waits for all flushed batches to run.
+func (b *Batcher[T]) drain() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for b.inflight > 0 {
+		b.drained.Wait()
+	}
+}
@@ -0,0 +1,220 @@
+package batch_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/batch"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a batch function recording the batches it runs.
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]int
+}
+
+func (r *recorder) run(_ context.Context, items []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.batches = append(r.batches, slices.Clone(items))
+
+	return nil
+}
+
+func (r *recorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sizes := make([]int, len(r.batches))
+	for i, b := range r.batches {
+		sizes[i] = len(b)
+	}
+
+	return sizes
+}
+
+func TestBatcher_Add(t *testing.T) {
+	t.Parallel()
+
+	t.Run("flushes full batches", func(t *testing.T) {
+		t.Parallel()
+
+		var rec recorder
+		b := batch.New(rec.run, batch.MaxSize(3), batch.MaxWait(0), batch.MaxConcurrency(1))
+
+		results := make([]*batch.Result, 7)
+		for i := range results {
+			results[i] = b.Add(i)
+		}
+		for _, result := range results[:6] {
+			require.NoError(t, result.Wait(context.Background()))
+		}
+		require.Equal(t, []int{3, 3}, rec.sizes(), "The last item should wait for its batch to fill")
+
+		b.Close()
+
+		require.NoError(t, results[6].Wait(context.Background()))
+		require.Equal(t, []int{3, 3, 1}, rec.sizes(), "Close should flush the pending items")
+	})
+
+	t.Run("flushes partial batches after the maximum wait", func(t *testing.T) {
+		t.Parallel()
+
+		var rec recorder
+		b := batch.New(rec.run, batch.MaxSize(100), batch.MaxWait(10*time.Millisecond))
+		defer b.Close()
+
+		first, second := b.Add(1), b.Add(2)
+
+		select {
+		case <-second.Done():
+		case <-time.After(time.Second):
+			require.Fail(t, "The batch should be flushed after the maximum wait")
+		}
+		require.NoError(t, first.Wait(context.Background()))
+		require.Equal(t, []int{2}, rec.sizes())
+	})
+
+	t.Run("fans back the batch error", func(t *testing.T) {
+		t.Parallel()
+
+		expectedError := errors.New("batch error")
+		b := batch.New(func(context.Context, []int) error { return expectedError }, batch.MaxSize(2))
+		defer b.Close()
+
+		first, second := b.Add(1), b.Add(2)
+
+		require.ErrorIs(t, first.Wait(context.Background()), expectedError)
+		require.ErrorIs(t, second.Wait(context.Background()), expectedError)
+	})
+
+	t.Run("fans back item errors", func(t *testing.T) {
+		t.Parallel()
+
+		expectedError := errors.New("item error")
+		b := batch.New(func(_ context.Context, items []int) error {
+			errs := make(batch.Errors, len(items))
+			for i, item := range items {
+				if item%2 == 0 {
+					errs[i] = expectedError
+				}
+			}
+
+			return errs
+		}, batch.MaxSize(2))
+		defer b.Close()
+
+		odd, even := b.Add(1), b.Add(2)
+
+		require.NoError(t, odd.Wait(context.Background()))
+		require.ErrorIs(t, even.Wait(context.Background()), expectedError)
+	})
+
+	t.Run("unwraps item errors of a mismatched length", func(t *testing.T) {
+		t.Parallel()
+
+		expectedError := errors.New("item error")
+		b := batch.New(func(context.Context, []int) error {
+			return batch.Errors{expectedError} // One error for two items.
+		}, batch.MaxSize(2))
+		defer b.Close()
+
+		first, second := b.Add(1), b.Add(2)
+
+		require.ErrorIs(t, first.Wait(context.Background()), expectedError)
+		require.ErrorIs(t, second.Wait(context.Background()), expectedError)
+	})
+
+	t.Run("fails the items of a panicking batch", func(t *testing.T) {
+		t.Parallel()
+
+		b := batch.New(func(context.Context, []int) error { panic("test panic") }, batch.MaxSize(1))
+		defer b.Close()
+
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, b.Add(1).Wait(context.Background()), &pe)
+		require.Equal(t, "test panic", pe.Value)
+	})
+
+	t.Run("limits the number of concurrent batches", func(t *testing.T) {
+		t.Parallel()
+
+		var running, peak atomic.Int64
+		b := batch.New(func(context.Context, []int) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+
+			return nil
+		}, batch.MaxSize(1), batch.MaxConcurrency(2))
+
+		for i := 0; i < 20; i++ {
+			b.Add(i)
+		}
+		b.Close()
+
+		require.LessOrEqual(t, peak.Load(), int64(2))
+	})
+
+	t.Run("fails items added after close", func(t *testing.T) {
+		t.Parallel()
+
+		var rec recorder
+		b := batch.New(rec.run)
+		b.Close()
+
+		require.ErrorIs(t, b.Add(1).Wait(context.Background()), batch.ErrClosed)
+		require.Empty(t, rec.sizes())
+	})
+
+	t.Run("fails pending items once the context is cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var rec recorder
+		b := batch.New(rec.run, batch.Context(ctx), batch.MaxWait(0))
+
+		result := b.Add(1)
+		cancel()
+		b.Close()
+
+		require.ErrorIs(t, result.Wait(context.Background()), context.Canceled)
+		require.Empty(t, rec.sizes())
+	})
+}
+
+func TestBatcher_Flush(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs the pending batch and waits for it", func(t *testing.T) {
+		t.Parallel()
+
+		var rec recorder
+		b := batch.New(rec.run, batch.MaxWait(0))
+		defer b.Close()
+
+		result := b.Add(1)
+		b.Add(2)
+		b.Flush()
+
+		select {
+		case <-result.Done():
+		default:
+			require.Fail(t, "Flush should wait for the batch to run")
+		}
+		require.Equal(t, []int{2}, rec.sizes())
+
+		b.Flush() // Nothing is pending.
+		require.Equal(t, []int{2}, rec.sizes())
+	})
+}
@@ -0,0 +1,67 @@
+package batch
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// Option represents an option that can be passed when instantiating a Batcher to customize it.
+type Option func(cfg *config)
+
+// config holds the settings of a Batcher.
+type config struct {
+	ctx            context.Context // ctx is the context handed to the batch function.
+	maxSize        int             // maxSize is the number of items that triggers a flush.
+	maxWait        time.Duration   // maxWait is the time after which a partial batch is flushed.
+	maxConcurrency int             // maxConcurrency is the maximum number of batches run at once.
+}
+
+// newConfig returns the settings of a Batcher with the provided options applied.
+func newConfig(options []Option) config {
+	cfg := config{
+		ctx:            context.Background(),
+		maxSize:        100,
+		maxWait:        100 * time.Millisecond,
+		maxConcurrency: runtime.GOMAXPROCS(0),
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// Context sets the context handed to the batch function. Once it is done,
+// the items of batches that have not started fail with its error.
+func Context(ctx context.Context) Option {
+	return func(cfg *config) {
+		cfg.ctx = ctx
+	}
+}
+
+// MaxSize sets the number of items that flushes a batch. It defaults to 100.
+func MaxSize(size int) Option {
+	return func(cfg *config) {
+		cfg.maxSize = max(size, 1)
+	}
+}
+
+// MaxWait sets the time after which a batch is flushed even if it is not full,
+// counted from its first item. A duration below or equal to zero flushes batches
+// only when they are full or on Flush and Close. It defaults to 100ms.
+func MaxWait(wait time.Duration) Option {
+	return func(cfg *config) {
+		cfg.maxWait = wait
+	}
+}
+
+// MaxConcurrency sets the maximum number of batches run at once.
+// A limit below 1 removes the limit. It defaults to runtime.GOMAXPROCS(0). While all of them are busy,
+// the flush of a batch blocks, and so does the Add that filled it.
+func MaxConcurrency(limit int) Option {
+	return func(cfg *config) {
+		cfg.maxConcurrency = limit
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/safeblock-dev/wr/batch"
+)
+
+func main() {
+	// Create a batcher running up to three items per batch, or the items added within 10ms.
+	b := batch.New(func(_ context.Context, ids []int) error {
+		log.Println("saving batch:", ids)
+
+		return nil // Return batch.Errors to fail some items only.
+	}, batch.MaxSize(3), batch.MaxWait(10*time.Millisecond))
+	defer b.Close() // Run the remaining items before exiting.
+
+	// Add items one at a time and wait for their batches to run.
+	results := make([]*batch.Result, 0, 5)
+	for id := 1; id <= 5; id++ {
+		results = append(results, b.Add(id))
+	}
+	for _, result := range results {
+		if err := result.Wait(context.Background()); err != nil {
+			log.Println("error:", err)
+		}
+	}
+}
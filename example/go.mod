@@ -1,6 +1,6 @@
 module example
 
-go 1.22.0
+go 1.23.0
 
 replace github.com/safeblock-dev/wr => ./..
 
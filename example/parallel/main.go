@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/safeblock-dev/wr/parallel"
+)
+
+func main() {
+	ctx := context.Background()
+	words := []string{"alpha", "beta", "gamma", "delta"}
+
+	// Map the words concurrently, at most two at a time; the outputs keep the input order.
+	upper, err := parallel.Map(ctx, words, func(_ context.Context, word string) string {
+		return strings.ToUpper(word)
+	}, parallel.Limit(2))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("upper:", upper)
+
+	// Sum the lengths of the words of a map.
+	lengths, err := parallel.ReduceMap(ctx, map[string]string{"first": "alpha", "second": "beta"}, 0,
+		func(_ context.Context, _ string, word string) (int, error) { return len(word), nil },
+		func(acc, value int) int { return acc + value },
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("total length:", lengths)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/tracing"
+)
+
+func main() {
+	// Record the spans in memory; an adapter for a tracing library implements tracing.Tracer the same way.
+	recorder := tracing.NewRecorder()
+	pool := gopool.New(gopool.Tracer(recorder))
+
+	// The task receives the execute span, a child of the submit span, through its context.
+	pool.GoCtx(func(context.Context) error {
+		log.Println("running traced task")
+
+		return nil
+	})
+	pool.Wait()
+
+	for _, span := range recorder.Spans() {
+		log.Printf("span %d %q, parent %d", span.ID, span.Name, span.ParentID)
+	}
+}
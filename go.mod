@@ -1,6 +1,6 @@
 module github.com/safeblock-dev/wr
 
-go 1.22.0
+go 1.23.0
 
 require (
 	github.com/safeblock-dev/werr v0.0.8
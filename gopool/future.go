@@ -0,0 +1,122 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// States of a Future.
+const (
+	futurePending int32 = iota
+	futureRunning
+	futureCancelled
+)
+
+// Future is the handle of a task submitted with Submit. It delivers the task's result
+// and cancels the task independently of the other tasks in the pool.
+type Future[T any] struct {
+	ctx       context.Context    // ctx is cancelled by Cancel to stop the task.
+	cancel    context.CancelFunc // cancel cancels ctx.
+	state     atomic.Int32       // state is one of futurePending, futureRunning or futureCancelled.
+	done      chan struct{}      // done is closed once the result is available.
+	once      sync.Once          // once ensures that the result is set only once.
+	mu        sync.Mutex         // mu protects value and completed.
+	value     T                  // value is the value returned by the task.
+	err       error              // err is the final error of the task.
+	completed bool               // completed indicates if the result is set.
+}
+
+// Submit submits a task to be run in the pool and returns its Future. The task receives
+// the pool's task context, which is also cancelled by the Future's Cancel. The error of
+// a failed task is also passed to the pool's error handler. If the task is never run,
+// because it is rejected, the Future completes with the rejection error.
+// A panic in the task completes the Future with a *syncgroup.PanicError.
+func Submit[T any](pool *Pool, f func(ctx context.Context) (T, error)) *Future[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	future := &Future[T]{ //nolint: exhaustruct
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	t := task{runCtx: future.run(f), complete: future.complete, skip: future.skip} //nolint: exhaustruct
+	err := pool.submit(context.Background(), t)
+	if errors.Is(err, ErrQueueFull) && pool.errorHandler != nil {
+		pool.errorHandler(err)
+	}
+
+	return future
+}
+
+// Await waits for the task to complete and returns its result.
+// If ctx is done first, it returns the zero value and the context error; the task keeps running.
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+
+		return zero, ctx.Err()
+	}
+}
+
+// Done returns a channel that is closed once the result of the task is available.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Cancel cancels the context of the task. A task that has not started yet is not run
+// nor reported to the pool's error handler, and its Future completes with
+// context.Canceled right away. The pool's Stats count it as rejected.
+func (f *Future[T]) Cancel() {
+	f.cancel()
+	if f.state.CompareAndSwap(futurePending, futureCancelled) {
+		f.complete(context.Canceled)
+	}
+}
+
+// run returns the task function, running fn with a context cancelled by Cancel.
+func (f *Future[T]) run(fn func(ctx context.Context) (T, error)) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(f.ctx, cancel)
+		defer stop()
+
+		value, err := fn(ctx)
+		if err == nil {
+			f.mu.Lock()
+			if !f.completed {
+				f.value = value // An abandoned attempt may return after the Future completed.
+			}
+			f.mu.Unlock()
+		}
+
+		return err
+	}
+}
+
+// skip marks the task as running and reports whether it was cancelled before it started.
+func (f *Future[T]) skip() bool {
+	return !f.state.CompareAndSwap(futurePending, futureRunning)
+}
+
+// complete sets the final error of the task and releases the waiters.
+func (f *Future[T]) complete(err error) {
+	f.once.Do(func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if err != nil {
+			var zero T
+			f.value = zero
+		}
+		f.err = err
+		f.completed = true
+		f.cancel() // Release the resources of ctx.
+		close(f.done)
+	})
+}
@@ -0,0 +1,160 @@
+package gopool_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSubmit tests futures returned by gopool.Submit.
+func TestSubmit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns the result of the task", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(2))
+		defer pool.Wait()
+
+		futures := make([]*gopool.Future[int], 5)
+		for i := range futures {
+			futures[i] = gopool.Submit(pool, func(context.Context) (int, error) { return i * i, nil })
+		}
+
+		for i, future := range futures {
+			value, err := future.Await(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, i*i, value)
+		}
+	})
+
+	t.Run("returns the error of the task", func(t *testing.T) {
+		t.Parallel()
+
+		expectedError := errors.New("task error")
+		var handledErr atomic.Value
+		pool := gopool.New(gopool.ErrorHandler(func(err error) { handledErr.Store(err) }))
+
+		future := gopool.Submit(pool, func(context.Context) (int, error) { return 1, expectedError })
+		<-future.Done()
+		pool.Wait()
+
+		value, err := future.Await(context.Background())
+		require.ErrorIs(t, err, expectedError)
+		require.Zero(t, value)
+		require.Equal(t, expectedError, handledErr.Load(), "The error should also be passed to the error handler")
+	})
+
+	t.Run("cancels only its own task", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New()
+		defer pool.Wait()
+
+		started := make(chan struct{})
+		cancelled := gopool.Submit(pool, func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+
+			return 0, ctx.Err()
+		})
+		other := gopool.Submit(pool, func(ctx context.Context) (int, error) {
+			<-started
+			time.Sleep(10 * time.Millisecond)
+
+			return 1, ctx.Err()
+		})
+
+		<-started
+		cancelled.Cancel()
+
+		_, err := cancelled.Await(context.Background())
+		require.ErrorIs(t, err, context.Canceled)
+		value, err := other.Await(context.Background())
+		require.NoError(t, err, "Other tasks should not be cancelled")
+		require.Equal(t, 1, value)
+	})
+
+	t.Run("does not run a task cancelled before it started", func(t *testing.T) {
+		t.Parallel()
+
+		var handled atomic.Int64
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.ErrorHandler(func(error) { handled.Add(1) }),
+		)
+		release := make(chan struct{})
+		pool.Go(func() error { <-release; return nil })
+
+		var ran atomic.Bool
+		future := gopool.Submit(pool, func(context.Context) (int, error) { ran.Store(true); return 1, nil })
+		future.Cancel()
+
+		select {
+		case <-future.Done():
+		case <-time.After(time.Second):
+			require.Fail(t, "The future should complete once cancelled")
+		}
+		close(release)
+		pool.Wait()
+
+		_, err := future.Await(context.Background())
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, ran.Load())
+		require.Zero(t, handled.Load(), "A skipped task should not be reported to the error handler")
+		stats := pool.Stats()
+		require.Zero(t, stats.Failed, "A skipped task should not count as failed")
+		require.EqualValues(t, 2, stats.Submitted)
+		require.EqualValues(t, 1, stats.Completed)
+		require.EqualValues(t, 1, stats.Rejected, "A skipped task should count as rejected")
+	})
+
+	t.Run("completes with the rejection error", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New()
+		pool.Wait()
+
+		future := gopool.Submit(pool, func(context.Context) (int, error) { return 1, nil })
+
+		_, err := future.Await(context.Background())
+		require.ErrorIs(t, err, gopool.ErrPoolClosed)
+	})
+
+	t.Run("completes with a panic", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.PanicHandler(func(any) {}))
+
+		future := gopool.Submit(pool, func(context.Context) (int, error) { panic("test panic") })
+		pool.Wait()
+
+		_, err := future.Await(context.Background())
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, err, &pe)
+		require.Equal(t, "test panic", pe.Value)
+	})
+
+	t.Run("stops awaiting when the context is done", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New()
+		defer pool.Wait()
+
+		release := make(chan struct{})
+		defer close(release)
+		future := gopool.Submit(pool, func(context.Context) (int, error) { <-release; return 1, nil })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := future.Await(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
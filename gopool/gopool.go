@@ -2,119 +2,747 @@ package gopool
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/safeblock-dev/wr/internal/leak"
+	"github.com/safeblock-dev/wr/internal/ratelimit"
+	"github.com/safeblock-dev/wr/internal/taskstats"
 	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/safeblock-dev/wr/tracing"
 )
 
 // Pool manages a pool of goroutines that can execute tasks concurrently.
 type Pool struct {
-	ctx          context.Context      // ctx is the pool's context.
-	parentCtx    context.Context      // parentCtx is the parent context of the pool.
-	group        *syncgroup.WaitGroup // group is the wait group managing goroutines.
-	cancelFunc   context.CancelFunc   // cancelFunc cancels the pool's context.
-	limiter      limiter              // limiter controls the number of concurrent goroutines.
-	tasks        chan func() error    // tasks is a channel for task functions.
-	errorHandler func(err error)      // errorHandler handles errors encountered during task execution.
-	panicHandler func(pc any)         // panicHandler handles panics recovered during task execution.
-	stopped      atomic.Bool          // stopped indicates if the pool has been stopped.
+	mu              sync.RWMutex         // mu prevents the task channel from being closed while tasks are submitted.
+	ctx             context.Context      // ctx is the pool's context, it is cancelled when the pool stops accepting tasks.
+	taskCtx         context.Context      // taskCtx is the context handed to tasks submitted with GoCtx.
+	parentCtx       context.Context      // parentCtx is the parent context of the pool.
+	group           *syncgroup.WaitGroup // group is the wait group managing goroutines.
+	cancelFunc      context.CancelFunc   // cancelFunc cancels the pool's context.
+	taskCancel      context.CancelFunc   // taskCancel cancels the task context.
+	limiter         *limiter             // limiter controls the number of concurrent goroutines.
+	tasks           chan task            // tasks is a channel for submitted tasks.
+	queueSize       int                  // queueSize is the capacity of the task queue.
+	rejection       Rejection            // rejection is the policy applied when the task queue is full.
+	taskTimeout     time.Duration        // taskTimeout is the maximum execution time of a task.
+	retry           *RetryPolicy         // retry is the policy for retrying failed tasks.
+	rateLimiter     *ratelimit.Limiter   // rateLimiter limits the rate at which tasks are started.
+	middleware      []MiddlewareFunc     // middleware wraps every task run by the pool.
+	workerInit      func() (any, error)  // workerInit creates the state of a worker.
+	workerClose     func(state any)      // workerClose releases the state of a worker.
+	tracer          tracing.Tracer       // tracer starts the spans of submitted and executed tasks.
+	logger          *slog.Logger         // logger is the logger of the default panic and error handlers.
+	name            string               // name is the name of the pool in log records.
+	taskIDs         atomic.Uint64        // taskIDs is the number of the last submitted task.
+	leaks           *leak.Detector       // leaks reports tasks still running when Wait takes too long.
+	idleTimeout     time.Duration        // idleTimeout is the time after which an idle worker exits.
+	minWorkers      int                  // minWorkers is the number of workers kept alive when idle.
+	keys            keyedQueues          // keys holds the tasks waiting for their key.
+	workers         atomic.Int64         // workers is the number of running workers.
+	stats           taskstats.Counters   // stats collects the statistics of the pool.
+	errorHandler    func(err error)      // errorHandler handles errors encountered during task execution.
+	rejectedHandler RejectedHandler      // rejectedHandler receives tasks that will never run.
+	panicHandler    func(pc any)         // panicHandler handles panics recovered during task execution.
+	stopped         atomic.Bool          // stopped indicates if the pool has been stopped.
+	done            chan struct{}        // done is closed once the pool has been stopped.
 }
 
 // New creates a new Pool with the provided options.
 func New(options ...Option) *Pool {
 	pool := &Pool{ //nolint: exhaustruct
-		panicHandler: defaultPanicHandler, // Set default panic handler.
-		tasks:        make(chan func() error),
+		tracer: tracing.Noop,
+		done:   make(chan struct{}),
 	}
+	pool.panicHandler = pool.logPanic // Set default panic handler.
 
 	// Apply all options.
 	for _, opt := range options {
 		opt(pool)
 	}
 
+	// Log task errors if a logger is set without an error handler.
+	if pool.errorHandler == nil && pool.logger != nil {
+		pool.errorHandler = pool.logError
+	}
+
 	// Initialize base context (if not already set).
 	if pool.ctx == nil {
 		Context(context.Background())(pool)
 	}
 
+	// Initialize limiter (if not already set).
+	if pool.limiter == nil {
+		MaxGoroutines(0)(pool)
+	}
+
+	// Initialize task queue.
+	pool.tasks = pool.newTasks()
+
 	// Initialize wait group with panic handler.
 	pool.group = syncgroup.New(syncgroup.PanicHandler(pool.panicHandler))
 
+	// Start the warm floor of workers.
+	pool.spawnMinWorkers()
+
 	return pool
 }
 
 // Go submits a task to be run in the pool. If all goroutines in the pool
 // are busy, a call to Go() will block until the task can be started.
-// Note: If this function is called after Wait(), it will cause a panic.
+// If the task queue is full, the pool's rejection policy is applied; with
+// RejectError the resulting ErrQueueFull is passed to the error handler.
+// A task submitted after Wait() or Cancel() is not run; it is passed
+// to the OnRejected handler instead.
 func (p *Pool) Go(f func() error) {
-	if p.ctx.Err() != nil {
-		return // Return if the pool's context is canceled.
+	err := p.submit(context.Background(), task{run: f}) //nolint: exhaustruct
+	if errors.Is(err, ErrQueueFull) && p.errorHandler != nil {
+		p.errorHandler(err)
+	}
+}
+
+// TryGo submits a task to be run in the pool only if it can be started or queued
+// immediately. It returns false without blocking if all goroutines in the pool
+// are busy and the queue is full, or the pool is closed or cancelled.
+// The rejection policy and the OnRejected handler are not applied.
+func (p *Pool) TryGo(f func() error) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closedErr() != nil {
+		return false
+	}
+
+	t := task{run: f} //nolint: exhaustruct
+	span := p.startSubmit(&t)
+	defer span.End()
+
+	if !p.dispatch(t) {
+		return false
+	}
+	p.stats.Submitted.Add(1)
+
+	return true
+}
+
+// GoContext submits a task to be run in the pool. If all goroutines in the pool
+// are busy and the queue is full, the pool's rejection policy is applied; with
+// RejectBlock it blocks until the task can be started, the pool is cancelled or
+// ctx is done. It returns ErrPoolClosed, ErrPoolCancelled, ErrQueueFull or the
+// context error if the task was not submitted.
+func (p *Pool) GoContext(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return p.submit(ctx, task{run: f, ctx: ctx}) //nolint: exhaustruct
+}
+
+// GoTimeout submits a task to be run in the pool. If all goroutines in the pool
+// are busy, it blocks for at most timeout waiting for the task to be started.
+// It returns context.DeadlineExceeded if the task was not submitted in time.
+// See GoContext for the handling of a full queue.
+func (p *Pool) GoTimeout(f func() error, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return p.GoContext(ctx, f)
+}
+
+// GoCtx submits a task to be run in the pool, handing it the pool's task context.
+// The context is cancelled by Cancel() or the parent context, so long-running tasks
+// can stop promptly instead of running to completion. It is also cancelled once Wait()
+// has finished. If the pool has a TaskTimeout, the context carries the task's deadline.
+func (p *Pool) GoCtx(f func(ctx context.Context) error) {
+	p.GoCtxTimeout(f, 0)
+}
+
+// GoCtxTimeout is like GoCtx, but overrides the pool's TaskTimeout for this task.
+// A timeout below or equal to zero keeps the pool's TaskTimeout.
+func (p *Pool) GoCtxTimeout(f func(ctx context.Context) error, timeout time.Duration) {
+	err := p.submit(context.Background(), task{runCtx: f, timeout: timeout}) //nolint: exhaustruct
+	if errors.Is(err, ErrQueueFull) && p.errorHandler != nil {
+		p.errorHandler(err)
+	}
+}
+
+// submit hands a task to the pool and accounts for it in the statistics.
+// Tasks that will never run are reported to the OnRejected handler, unless
+// the submitter gave up because ctx is done.
+func (p *Pool) submit(ctx context.Context, t task) error {
+	span := p.startSubmit(&t)
+	defer span.End()
+
+	var dropped []task
+	err := p.enqueue(ctx, t, &dropped)
+
+	for _, d := range dropped {
+		p.stats.Rejected.Add(1)
+		p.rejected(d, ErrTaskDropped)
+	}
+
+	if err != nil && !errors.Is(err, errCallerRuns) {
+		span.RecordError(err)
+	}
+
+	switch {
+	case err == nil:
+		p.stats.Submitted.Add(1)
+	case errors.Is(err, errCallerRuns):
+		p.stats.Submitted.Add(1)
+		p.callerRun(t)
+
+		return nil
+	case errors.Is(err, ErrTaskDropped):
+		p.stats.Rejected.Add(1)
+		p.rejected(t, err)
+
+		return nil // The task is discarded silently.
+	case errors.Is(err, ErrQueueFull):
+		p.stats.Rejected.Add(1)
+		p.rejected(t, err)
+	case errors.Is(err, ErrPoolClosed), errors.Is(err, ErrPoolCancelled):
+		p.rejected(t, err)
+	}
+
+	return err
+}
+
+// startSubmit starts the submit span of a task as a child of the submitter's context,
+// or of the pool's context for tasks submitted without one.
+func (p *Pool) startSubmit(t *task) tracing.Span {
+	parent := t.ctx
+	if parent == nil {
+		parent = p.parentCtx
+	}
+
+	var span tracing.Span
+	t.ctx, span = p.tracer.Start(parent, "gopool.submit")
+	t.id = p.taskIDs.Add(1)
+	if t.site == "" {
+		t.site = p.leaks.CallSite()
+	}
+
+	return span
+}
+
+// enqueue dispatches a task unless the pool is closed or cancelled. It holds the read
+// lock so that the task channel is not closed while the task is being sent.
+// Queued tasks discarded to make room for the task are appended to dropped.
+func (p *Pool) enqueue(ctx context.Context, t task, dropped *[]task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if err := p.closedErr(); err != nil {
+		return err
 	}
 
-	if p.limiter == nil {
+	if p.dispatch(t) {
+		return nil
+	}
+
+	return p.reject(ctx, t, dropped)
+}
+
+// closedErr returns ErrPoolClosed if the pool has been stopped, ErrPoolCancelled
+// if its context has been cancelled and nil if it accepts tasks.
+func (p *Pool) closedErr() error {
+	switch {
+	case p.stopped.Load():
+		return ErrPoolClosed
+	case p.ctx.Err() != nil:
+		return ErrPoolCancelled
+	default:
+		return nil
+	}
+}
+
+// rejected passes a task that will never run to the OnRejected handler.
+// The next task waiting for the key of a rejected keyed task is submitted in its place.
+func (p *Pool) rejected(t task, err error) {
+	if t.keyed {
+		defer p.skipKeyed(t.key)
+	}
+
+	t.finish(err)
+	if p.rejectedHandler != nil {
+		p.rejectedHandler(p.ctxFunc(t), err)
+	}
+}
+
+// dispatch hands a task to a worker, spawning a new one if the limit allows it,
+// or puts it in the queue. It reports whether the task was accepted.
+func (p *Pool) dispatch(t task) bool {
+	if p.limiter.limit() == 0 {
 		// No limit on the number of goroutines.
 		select {
-		case p.tasks <- f:
+		case p.tasks <- t:
 			// A goroutine is available to handle the task.
+			p.ensureWorker()
+
+			return true
 		default:
+		}
+
+		if p.limiter.tryAcquire() {
 			// No goroutine was available to handle the task.
-			// Spawn a new one and send it the task.
-			p.group.Go(p.worker)
-			// We know there is at least one worker running, so wait
-			// for it to become available. This ensures we never spawn
-			// more workers than the number of tasks.
-			p.tasks <- f
-		}
-	} else {
+			// Spawn a new one running the task.
+			p.spawnWith(t)
+
+			return true
+		}
+		// A limit was set concurrently; fall back to the limited path.
+	}
+
+	return p.trySubmit(t)
+}
+
+// trySubmit hands a task to an idle or new worker, or puts it in the queue,
+// without blocking. It reports whether the task was accepted.
+func (p *Pool) trySubmit(t task) bool {
+	if cap(p.tasks) == 0 {
 		select {
-		case p.limiter <- struct{}{}:
-			// If we are below our limit, spawn a new worker rather
-			// than waiting for one to become available.
-			p.group.Go(p.worker)
-			p.tasks <- f
-		case <-p.ctx.Done():
-			// Context was cancelled; return without adding the task.
-		case p.tasks <- f:
-			// A worker is available and has accepted the task.
+		case p.tasks <- t:
+			// An idle worker has accepted the task.
+			return true
+		default:
 		}
 	}
+
+	if p.limiter.tryAcquire() {
+		// If we are below our limit, spawn a new worker rather
+		// than waiting for one to become available.
+		p.spawnWith(t)
+
+		return true
+	}
+
+	select {
+	case p.tasks <- t:
+		// A worker has accepted the task or it was queued.
+		p.ensureWorker()
+
+		return true
+	default:
+		return false
+	}
+}
+
+// reject applies the rejection policy to a task that could not be accepted.
+// Queued tasks discarded by RejectDropOldest are appended to dropped.
+func (p *Pool) reject(ctx context.Context, t task, dropped *[]task) error {
+	switch p.rejection {
+	case RejectBlock:
+		return p.block(ctx, t)
+	case RejectDropNewest:
+		return ErrTaskDropped // Discard the submitted task.
+	case RejectDropOldest:
+		if cap(p.tasks) == 0 {
+			// There is no queue to drop from; discard the submitted task.
+			return ErrTaskDropped
+		}
+		// Discard queued tasks until the submitted one fits.
+		for {
+			select {
+			case p.tasks <- t:
+				return nil
+			default:
+			}
+			select {
+			case oldest := <-p.tasks:
+				if oldest.retired != nil {
+					close(oldest.retired) // Not a task; the weighted task asks another worker.
+
+					continue
+				}
+				*dropped = append(*dropped, oldest)
+			default:
+			}
+		}
+	case RejectCallerRuns:
+		return errCallerRuns // Run the task once the lock is released.
+	case RejectError:
+		return ErrQueueFull
+	}
+
+	return nil
+}
+
+// block waits until a worker accepts the task, the task is queued or a new
+// worker can be spawned. It gives up when the pool's context is cancelled or ctx is done.
+func (p *Pool) block(ctx context.Context, t task) error {
+	w := p.limiter.wait(1)
+
+	select {
+	case <-w.ready:
+		// A permit was granted; spawn a new worker for the task.
+		p.spawnWith(t)
+
+		return nil
+	case p.tasks <- t:
+		// A worker has accepted the task or it was queued.
+		p.limiter.cancel(w)
+		p.ensureWorker()
+
+		return nil
+	case <-p.ctx.Done():
+		// Context was cancelled; return without adding the task.
+		p.limiter.cancel(w)
+
+		return p.closedErr()
+	case <-ctx.Done():
+		// Caller gave up; return without adding the task.
+		p.limiter.cancel(w)
+
+		return ctx.Err()
+	}
+}
+
+// ensureWorker spawns a worker if the limit allows it, so that queued tasks
+// are never left without a worker.
+func (p *Pool) ensureWorker() {
+	if cap(p.tasks) == 0 {
+		return // Unbuffered channel, the task was accepted by a worker.
+	}
+
+	if p.limiter.tryAcquire() {
+		p.spawn()
+	}
+}
+
+// spawn starts a new worker holding an acquired slot.
+func (p *Pool) spawn() {
+	p.workers.Add(1)
+	p.group.Go(func() { p.worker(nil) })
+}
+
+// spawnWith starts a new worker holding an acquired slot, which runs t before receiving
+// tasks: sending t through the task channel could hand the worker a request to exit instead,
+// leaving the task without a worker. Only the spawned worker's copy of t escapes to the heap.
+func (p *Pool) spawnWith(t task) {
+	p.workers.Add(1)
+	p.group.Go(func() { p.worker(&t) })
+}
+
+// callerRun executes a task in the calling goroutine, handling errors and panics
+// the same way a worker does.
+func (p *Pool) callerRun(t task) {
+	var state workerState
+	defer p.closeState(&state)
+
+	if t.keyed {
+		p.runKeyed(t, &state)
+
+		return
+	}
+
+	p.safeExecute(p.bind(t, &state))
+}
+
+// safeExecute executes a task, passing a panic to the panic handler instead of propagating it.
+func (p *Pool) safeExecute(t task) {
+	defer func() {
+		if pc := recover(); pc != nil && p.panicHandler != nil {
+			p.panicHandler(syncgroup.NewPanicError(pc))
+		}
+	}()
+
+	p.execute(t)
 }
 
 // Wait cleans up spawned goroutines, propagating any panics that were raised by the tasks.
+// Concurrent calls block until the pool has been stopped.
 func (p *Pool) Wait() {
-	if p.stopped.CompareAndSwap(false, true) {
-		p.cancelFunc()
-		close(p.tasks)
-		p.group.Wait()
-		p.limiter.close()
+	if !p.stopped.CompareAndSwap(false, true) {
+		<-p.done
+
+		return
+	}
+
+	p.cancelFunc()
+	// Wait for submitters to leave before closing the task channel.
+	p.mu.Lock()
+	close(p.tasks)
+	p.mu.Unlock()
+	stopWatch := p.leaks.Watch()
+	p.group.Wait()
+	stopWatch()
+	p.taskCancel()
+	close(p.done)
+}
+
+// Shutdown stops accepting tasks and waits for queued and running tasks to complete.
+// If ctx is done first, it cancels the context of running tasks and returns
+// a *ShutdownError with the number of tasks still in flight; the pool keeps
+// stopping in the background.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		p.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		stats := p.Stats()
+		p.Cancel()
+
+		return &ShutdownError{Running: stats.Running, Queued: stats.Queued, Err: ctx.Err()}
 	}
 }
 
-// Cancel cancels the pool's context.
+// Cancel cancels the pool's context and the context of running tasks.
 func (p *Pool) Cancel() {
-	p.cancelFunc()
+	p.taskCancel()
+}
+
+// SetMaxGoroutines changes the maximum number of goroutines allowed in the pool
+// while tasks are running. A limit below 1 removes the limit. Growing the limit
+// takes effect immediately; shrinking it takes effect as running workers finish their tasks.
+func (p *Pool) SetMaxGoroutines(limit int) {
+	p.limiter.setLimit(limit)
+}
+
+// Stats returns a snapshot of the pool's activity.
+func (p *Pool) Stats() Stats {
+	stats := p.stats.Snapshot()
+	stats.Workers = p.workers.Load()
+	// Tasks run by the caller are not executed by a worker.
+	stats.Idle = max(stats.Workers-stats.Running, 0)
+	stats.Queued = int64(len(p.tasks))
+
+	return stats
 }
 
 // Reset reactivates the pool, allowing new tasks to be submitted.
 func (p *Pool) Reset() {
 	p.Wait()
-	p.tasks = make(chan func() error)
-	if p.limiter != nil {
-		p.limiter = make(limiter, p.limiter.limit())
-	}
+	p.tasks = p.newTasks()
 	Context(p.parentCtx)(p)
+	p.done = make(chan struct{})
 	p.stopped.Store(false)
+	p.spawnMinWorkers()
 }
 
 // worker is the function run by each goroutine in the pool.
-// It executes tasks and handles panics.
-func (p *Pool) worker() {
-	defer p.limiter.release() // Release limiter when worker exits.
+// It executes first, if set, then the tasks it receives, and handles panics.
+func (p *Pool) worker(first *task) {
+	var state workerState
+	exited := false
+	defer func() {
+		if !exited {
+			p.workers.Add(-1)
+			p.limiter.release(1) // Release limiter when worker exits.
+		}
+		if len(p.tasks) > 0 {
+			// A task was queued while exiting, or the worker died from a panic
+			// with tasks left in the queue; make sure they get a worker.
+			p.ensureWorker()
+		}
+	}()
+
+	defer p.closeState(&state)
+
+	exited = p.run(first, &state)
+}
+
+// run executes first, if set, then tasks until the task channel is closed, the pool shrinks
+// below the number of running workers or, with an idle timeout, the worker has been idle for too long.
+// It reports whether the worker exited early, having already released its slot.
+func (p *Pool) run(first *task, state *workerState) bool {
+	if first != nil {
+		p.runTask(*first, state)
+		if p.shed() {
+			return true
+		}
+	}
+
+	if p.idleTimeout <= 0 {
+		for t := range p.tasks {
+			if t.retired != nil {
+				return p.yield(t)
+			}
+			p.runTask(t, state)
+			if p.shed() {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	timer := time.NewTimer(p.idleTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case t, ok := <-p.tasks:
+			if !ok {
+				return false
+			}
+			if t.retired != nil {
+				return p.yield(t)
+			}
+			p.runTask(t, state)
+			if p.shed() {
+				return true
+			}
+		case <-timer.C:
+			if p.retire() {
+				return true
+			}
+		}
+
+		// Restart the idle timer, draining it if it fired in the meantime.
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(p.idleTimeout)
+	}
+}
+
+// yield releases the worker's slot on request of a weighted task waiting for slots,
+// and reports that the worker should exit.
+func (p *Pool) yield(t task) bool {
+	p.workers.Add(-1)
+	p.limiter.release(1)
+	close(t.retired)
+
+	return true
+}
+
+// shed releases the worker's slot if more workers are running than the limit allows,
+// and reports whether the worker should exit.
+func (p *Pool) shed() bool {
+	if p.limiter.shed() {
+		p.workers.Add(-1)
+
+		return true
+	}
+
+	return false
+}
+
+// retire reports whether an idle worker may exit, keeping at least
+// the minimum number of workers alive. On success the worker's slot is released.
+func (p *Pool) retire() bool {
+	for {
+		if len(p.tasks) > 0 {
+			return false // There is queued work to do.
+		}
+
+		n := p.workers.Load()
+		if n <= int64(p.minWorkers) {
+			return false
+		}
+		if p.workers.CompareAndSwap(n, n-1) {
+			p.limiter.release(1)
+
+			return true
+		}
+	}
+}
 
-	for f := range p.tasks {
-		err := f()
-		if p.errorHandler != nil && err != nil {
-			p.errorHandler(err)
+// spawnMinWorkers starts the minimum number of workers, within the goroutine limit.
+func (p *Pool) spawnMinWorkers() {
+	for i := 0; i < p.minWorkers; i++ {
+		if !p.limiter.tryAcquire() {
+			return // The goroutine limit is reached.
 		}
+		p.spawn()
+	}
+}
+
+// execute runs a task, records its statistics and passes its error to the error handler.
+func (p *Pool) execute(t task) {
+	if t.skip != nil && t.skip() {
+		p.stats.Rejected.Add(1) // The submitter has already completed the task.
+
+		return
 	}
+
+	// Wait for the rate limit; once the pool is cancelled, the task runs right away.
+	p.rateLimiter.Wait(p.taskCtx)
+
+	spanCtx, span := p.tracer.Start(t.ctx, "gopool.execute")
+	defer span.End()
+	defer p.leaks.Start(t.site)()
+
+	// A task that timed out keeps the slot until it returns, after its error is reported.
+	var calls timedCalls
+	defer calls.wait()
+
+	start := p.stats.Start()
+	returned := false
+	defer func() {
+		if !returned {
+			p.stats.Finish(start, false, nil) // The task panicked.
+			if pc := recover(); pc != nil {
+				// Record the panic on the span before it propagates to the panic handler.
+				pe := t.panicError(pc)
+				span.RecordError(pe)
+				t.finish(pe)
+				panic(pe)
+			}
+		}
+	}()
+
+	ctx := p.taskCtx
+	if spanCtx != p.parentCtx {
+		// Hand the span and the submitter's values to the task.
+		ctx = tracing.Link(p.taskCtx, spanCtx)
+	}
+
+	err := p.callRetry(ctx, t, &calls)
+	returned = true
+	p.stats.Finish(start, true, err)
+	if err != nil {
+		span.RecordError(err)
+	}
+	t.finish(err)
+
+	if p.errorHandler != nil && err != nil {
+		p.errorHandler(err)
+	}
+}
+
+// newTasks creates the task channel. It is buffered by the queue size only when
+// the number of goroutines is limited, since otherwise every task gets a worker.
+func (p *Pool) newTasks() chan task {
+	if p.limiter.limit() == 0 {
+		return make(chan task)
+	}
+
+	return make(chan task, p.queueSize)
+}
+
+// log returns the logger of the pool, annotated with its name.
+func (p *Pool) log() *slog.Logger {
+	logger := p.logger
+	if logger == nil {
+		logger = syncgroup.DefaultLogger()
+	}
+	if p.name != "" {
+		logger = logger.With(slog.String("pool", p.name))
+	}
+
+	return logger
+}
+
+// logPanic is the default panic handler that logs the panic.
+func (p *Pool) logPanic(pc any) {
+	syncgroup.LogPanic(p.log(), pc)
+}
+
+// logError is the default error handler, used when a logger is set, that logs the error.
+func (p *Pool) logError(err error) {
+	p.log().Error("task failed", slog.Any("error", err))
 }
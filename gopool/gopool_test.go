@@ -2,14 +2,20 @@ package gopool_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"strconv"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/safeblock-dev/wr/tracing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -107,7 +113,411 @@ func TestPool_Go(t *testing.T) {
 	})
 }
 
+// TestPool_GoCtx tests the GoCtx method of the gopool.Pool.
+func TestPool_GoCtx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("context is alive during wait", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New()
+		var ctxErr atomic.Value
+
+		pool.GoCtx(func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			ctxErr.Store(fmt.Sprint(ctx.Err()))
+			return nil
+		})
+		pool.Wait()
+
+		require.Equal(t, "<nil>", ctxErr.Load(), "Task context should not be cancelled by Wait")
+	})
+
+	t.Run("cancel stops running task", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New()
+		started := make(chan struct{})
+		var ctxErr atomic.Value
+
+		pool.GoCtx(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			ctxErr.Store(ctx.Err())
+			return nil
+		})
+
+		<-started
+		pool.Cancel()
+		pool.Wait()
+
+		require.Equal(t, context.Canceled, ctxErr.Load())
+	})
+
+	t.Run("parent context cancels task", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		pool := gopool.New(gopool.Context(ctx))
+		started := make(chan struct{})
+
+		pool.GoCtx(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+
+		<-started
+		cancel()
+		pool.Wait()
+	})
+}
+
+// TestPool_TryGo tests the TryGo method of the gopool.Pool.
+func TestPool_TryGo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rejects when saturated", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(1))
+		release := make(chan struct{})
+
+		require.True(t, pool.TryGo(func() error { <-release; return nil }))
+		require.False(t, pool.TryGo(func() error { return nil }), "Task should be rejected while the pool is busy")
+
+		close(release)
+		pool.Wait()
+	})
+
+	t.Run("accepts without limit", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Uint64
+		pool := gopool.New()
+
+		for i := 0; i < 10; i++ {
+			require.True(t, pool.TryGo(func() error { counter.Add(1); return nil }))
+		}
+		pool.Wait()
+
+		require.EqualValues(t, 10, counter.Load())
+	})
+
+	t.Run("rejects after cancel", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New()
+		pool.Cancel()
+
+		require.False(t, pool.TryGo(func() error { return nil }))
+		pool.Wait()
+	})
+}
+
+// TestPool_GoContext tests the GoContext and GoTimeout methods of the gopool.Pool.
+func TestPool_GoContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("gives up after timeout", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(1))
+		release := make(chan struct{})
+
+		require.NoError(t, pool.GoTimeout(func() error { <-release; return nil }, time.Second))
+		err := pool.GoTimeout(func() error { return nil }, 10*time.Millisecond)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(release)
+		pool.Wait()
+	})
+
+	t.Run("returns context error", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(1))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := pool.GoContext(ctx, func() error { return nil })
+		require.ErrorIs(t, err, context.Canceled)
+		pool.Wait()
+	})
+
+	t.Run("returns error when pool is cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(1))
+		pool.Cancel()
+
+		err := pool.GoContext(context.Background(), func() error { return nil })
+		require.ErrorIs(t, err, gopool.ErrPoolCancelled)
+		require.ErrorIs(t, err, context.Canceled)
+		pool.Wait()
+	})
+}
+
+// TestPool_SetMaxGoroutines tests resizing the limit of the gopool.Pool at runtime.
+func TestPool_SetMaxGoroutines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("grow unblocks submission", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(1))
+		release := make(chan struct{})
+
+		pool.Go(func() error { <-release; return nil })
+		require.False(t, pool.TryGo(func() error { return nil }))
+
+		submitted := make(chan struct{})
+		go func() {
+			pool.Go(func() error { <-release; return nil })
+			close(submitted)
+		}()
+
+		pool.SetMaxGoroutines(2)
+		<-submitted
+
+		close(release)
+		pool.Wait()
+	})
+
+	t.Run("shrink takes effect as workers finish", func(t *testing.T) {
+		t.Parallel()
+
+		const maxConcurrent = 10
+		pool := gopool.New(gopool.MaxGoroutines(maxConcurrent))
+		var currentConcurrent, maxObserved atomic.Int64
+		task := func() error {
+			cur := currentConcurrent.Add(1)
+			for {
+				old := maxObserved.Load()
+				if cur <= old || maxObserved.CompareAndSwap(old, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			currentConcurrent.Add(-1)
+
+			return nil
+		}
+
+		// Occupy all workers, so every one of them is running when the limit shrinks.
+		release := make(chan struct{})
+		for i := 0; i < maxConcurrent; i++ {
+			pool.Go(func() error { <-release; return nil })
+		}
+
+		pool.SetMaxGoroutines(2)
+		close(release)
+		// Let the running workers finish and shed.
+		time.Sleep(10 * time.Millisecond)
+
+		for i := 0; i < maxConcurrent*5; i++ {
+			pool.Go(task)
+		}
+		pool.Wait()
+
+		require.LessOrEqual(t, maxObserved.Load(), int64(2), "Concurrency should not exceed the new limit")
+	})
+}
+
+// TestPool_RateLimit tests the rate limit of the gopool.Pool.
+func TestPool_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("limits task starts", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		pool := gopool.New(gopool.MaxGoroutines(5), gopool.RateLimit(200, 1))
+		start := time.Now()
+
+		for i := 0; i < 10; i++ {
+			pool.Go(func() error { counter.Add(1); return nil })
+		}
+		pool.Wait()
+
+		require.EqualValues(t, 10, counter.Load())
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond, "Tasks should be started at the given rate")
+	})
+
+	t.Run("runs tasks right away after cancel", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		pool := gopool.New(gopool.MaxGoroutines(1), gopool.QueueSize(5), gopool.RateLimit(0.01, 1))
+
+		for i := 0; i < 5; i++ {
+			pool.Go(func() error { counter.Add(1); return nil })
+		}
+		pool.Cancel()
+		pool.Wait()
+
+		require.EqualValues(t, 5, counter.Load())
+	})
+}
+
 // TestErrorHandler tests the error handling capability of the gopool.Pool.
+// TestPool_Middleware tests the task middleware of the gopool.Pool.
+func TestPool_Middleware(t *testing.T) {
+	t.Parallel()
+
+	// record returns a middleware appending its name to calls before and after the task.
+	record := func(calls *[]string, name string) gopool.MiddlewareFunc {
+		return func(next func() error) func() error {
+			return func() error {
+				*calls = append(*calls, name+" before")
+				err := next()
+				*calls = append(*calls, name+" after")
+
+				return err
+			}
+		}
+	}
+
+	t.Run("applies middleware in order", func(t *testing.T) {
+		t.Parallel()
+
+		var calls []string
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.Middleware(record(&calls, "outer"), record(&calls, "inner")),
+		)
+
+		pool.Go(func() error { calls = append(calls, "task"); return nil })
+		pool.GoCtx(func(context.Context) error { calls = append(calls, "ctx task"); return nil })
+		pool.Wait()
+
+		require.Equal(t, []string{
+			"outer before", "inner before", "task", "inner after", "outer after",
+			"outer before", "inner before", "ctx task", "inner after", "outer after",
+		}, calls)
+	})
+
+	t.Run("can replace the task result", func(t *testing.T) {
+		t.Parallel()
+
+		var handledErr atomic.Value
+		expectedError := errors.New("middleware error")
+		pool := gopool.New(
+			gopool.ErrorHandler(func(err error) { handledErr.Store(err) }),
+			gopool.Middleware(func(next func() error) func() error {
+				return func() error {
+					if err := next(); err != nil {
+						return fmt.Errorf("%w: %w", expectedError, err)
+					}
+
+					return nil
+				}
+			}),
+		)
+
+		pool.Go(func() error { return errors.New("task error") })
+		pool.Wait()
+
+		require.ErrorIs(t, handledErr.Load().(error), expectedError) //nolint: forcetypeassert
+	})
+
+	t.Run("wraps every retry attempt", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+		pool := gopool.New(
+			gopool.Retry(gopool.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+			gopool.Middleware(func(next func() error) func() error {
+				return func() error { calls.Add(1); return next() }
+			}),
+			gopool.ErrorHandler(func(error) {}),
+		)
+
+		pool.Go(func() error { return errors.New("task error") })
+		pool.Wait()
+
+		require.EqualValues(t, 3, calls.Load())
+	})
+}
+
+// TestPool_Tracer tests the tracing of tasks in the gopool.Pool.
+func TestPool_Tracer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("links execute span to submitter", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracing.NewRecorder()
+		pool := gopool.New(gopool.Tracer(recorder))
+		expectedError := errors.New("task error")
+
+		ctx, root := recorder.Start(context.Background(), "request")
+		require.NoError(t, pool.GoContext(ctx, func() error { return expectedError }))
+		pool.Wait()
+		root.End()
+
+		spans := recorder.Spans()
+		require.Len(t, spans, 3)
+		require.Equal(t, "gopool.submit", spans[1].Name)
+		require.Equal(t, spans[0].ID, spans[1].ParentID, "Submit span should be a child of the submitter's span")
+		require.Equal(t, "gopool.execute", spans[2].Name)
+		require.Equal(t, spans[1].ID, spans[2].ParentID, "Execute span should be a child of the submit span")
+		require.Equal(t, expectedError, spans[2].Err)
+		require.True(t, spans[1].Ended && spans[2].Ended)
+	})
+
+	t.Run("hands execute span to the task", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracing.NewRecorder()
+		pool := gopool.New(gopool.Tracer(recorder))
+
+		pool.GoCtx(func(ctx context.Context) error {
+			_, span := recorder.Start(ctx, "inner")
+			span.End()
+
+			return nil
+		})
+		pool.Wait()
+
+		spans := recorder.Spans()
+		require.Len(t, spans, 3)
+		require.Equal(t, "inner", spans[2].Name)
+		require.Equal(t, spans[1].ID, spans[2].ParentID, "Task span should be a child of the execute span")
+	})
+
+	t.Run("records panics", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracing.NewRecorder()
+		pool := gopool.New(gopool.Tracer(recorder), gopool.PanicHandler(func(any) {}))
+
+		pool.Go(func() error { panic("test panic") })
+		pool.Wait()
+
+		spans := recorder.Spans()
+		require.Len(t, spans, 2)
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, spans[1].Err, &pe)
+		require.Equal(t, "test panic", pe.Value)
+		require.True(t, spans[1].Ended)
+	})
+
+	t.Run("records rejections", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracing.NewRecorder()
+		pool := gopool.New(gopool.Tracer(recorder))
+		pool.Wait()
+
+		pool.Go(func() error { return nil })
+
+		spans := recorder.Spans()
+		require.Len(t, spans, 1)
+		require.Equal(t, gopool.ErrPoolClosed, spans[0].Err)
+	})
+}
+
 func TestErrorHandler(t *testing.T) {
 	t.Parallel()
 
@@ -228,6 +638,29 @@ func TestPool_Panics(t *testing.T) {
 
 		require.True(t, panicHandled, "Panic should be handled")
 	})
+
+	t.Run("runs queued tasks after a panic", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(10),
+			gopool.PanicHandler(func(any) {}),
+		)
+
+		pool.Go(func() error {
+			time.Sleep(20 * time.Millisecond)
+			panic("test panic")
+		})
+		var completed atomic.Int64
+		for i := 0; i < 5; i++ {
+			pool.Go(func() error { completed.Add(1); return nil })
+		}
+		pool.Wait()
+
+		require.EqualValues(t, 5, completed.Load(), "Queued tasks should get a new worker")
+		require.Zero(t, pool.Stats().Queued)
+	})
 }
 
 // TestPool_DoubleWait tests behavior of double Wait calls on gopool.Pool.
@@ -259,3 +692,75 @@ func TestPool_GoAfterWait(t *testing.T) {
 		}, "Go after Wait should not cause a panic")
 	})
 }
+
+// TestPool_Logger tests structured logging of the gopool.Pool.
+func TestPool_Logger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("logs panics with attributes", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+		pool := gopool.New(gopool.Logger(logger), gopool.Name("workers"))
+
+		pool.Go(func() error { return nil })
+		pool.Go(func() error { panic("test panic") })
+		pool.Wait()
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &record))
+		require.Equal(t, "ERROR", record["level"])
+		require.Equal(t, "workers", record["pool"])
+		require.Equal(t, "test panic", record["panic"])
+		require.EqualValues(t, 2, record["task_id"])
+		require.Contains(t, record["stack"], "gopool_test.TestPool_Logger")
+	})
+
+	t.Run("logs errors without an error handler", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+		pool := gopool.New(gopool.Logger(logger))
+
+		pool.Go(func() error { return errors.New("task error") })
+		pool.Wait()
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &record))
+		require.Equal(t, "task failed", record["msg"])
+		require.Equal(t, "task error", record["error"])
+	})
+}
+
+// TestPool_LeakDetector tests the reporting of tasks blocking Wait.
+func TestPool_LeakDetector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports tasks still running", func(t *testing.T) {
+		t.Parallel()
+
+		reports := make(chan syncgroup.Report, 1)
+		pool := gopool.New(gopool.MinGoroutines(2), gopool.LeakDetector(10*time.Millisecond, func(r syncgroup.Report) {
+			reports <- r
+		}))
+		release := make(chan struct{})
+
+		pool.Go(func() error { return nil })
+		pool.GoCtx(func(context.Context) error { <-release; return nil })
+		waited := make(chan struct{})
+		go func() {
+			pool.Wait()
+			close(waited)
+		}()
+
+		report := <-reports
+		close(release)
+		<-waited // The wait continues after the report.
+
+		require.Len(t, report.Tasks, 1, "Idle workers should not be reported")
+		require.Contains(t, report.Tasks[0].CallSite, "gopool_test.go:")
+		require.Contains(t, string(report.Tasks[0].Stack), "gopool_test.TestPool_LeakDetector")
+	})
+}
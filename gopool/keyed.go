@@ -0,0 +1,131 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// keyedQueues holds the tasks submitted with GoKeyed that wait for their key.
+// A key is present while one of its tasks is submitted or running.
+type keyedQueues struct {
+	mu      sync.Mutex        // mu protects pending.
+	pending map[string][]task // pending stores the waiting tasks of each active key in submission order.
+}
+
+// acquire activates the key of a task and reports whether it succeeded.
+// If the key is already active, the task is queued behind it.
+func (k *keyedQueues) acquire(t task) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if queue, ok := k.pending[t.key]; ok {
+		k.pending[t.key] = append(queue, t)
+
+		return false
+	}
+
+	if k.pending == nil {
+		k.pending = make(map[string][]task)
+	}
+	k.pending[t.key] = nil
+
+	return true
+}
+
+// next returns the next task waiting for key. If there is none, the key is released.
+func (k *keyedQueues) next(key string) (task, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	queue := k.pending[key]
+	if len(queue) == 0 {
+		delete(k.pending, key)
+
+		return task{}, false //nolint: exhaustruct
+	}
+
+	t := queue[0]
+	queue[0] = task{} //nolint: exhaustruct
+	k.pending[key] = queue[1:]
+
+	return t, true
+}
+
+// GoKeyed submits a task to be run in the pool. Tasks with the same key run one at a time
+// in submission order, while tasks with different keys run concurrently up to the goroutine limit.
+// A task waiting for its key does not occupy a worker: once the running task finishes,
+// the next one is handed to an idle worker, or run by the same worker if none is available.
+// A panic in a keyed task is passed to the panic handler without stopping its worker.
+func (p *Pool) GoKeyed(key string, f func() error) {
+	t := task{run: f, key: key, keyed: true, site: p.leaks.CallSite()} //nolint: exhaustruct
+
+	if !p.keys.acquire(t) {
+		return // The task waits for the running task with the same key.
+	}
+
+	err := p.submit(context.Background(), t)
+	if errors.Is(err, ErrQueueFull) && p.errorHandler != nil {
+		p.errorHandler(err)
+	}
+}
+
+// runTask executes a task received by a worker owning state.
+func (p *Pool) runTask(t task, state *workerState) {
+	if t.keyed {
+		p.runKeyed(t, state)
+
+		return
+	}
+
+	p.execute(p.bind(t, state))
+}
+
+// runKeyed executes a keyed task, then hands the next task waiting for its key to another
+// worker. If no worker can take it without blocking, the task is run in the calling goroutine.
+func (p *Pool) runKeyed(t task, state *workerState) {
+	for {
+		p.safeExecute(p.bind(t, state))
+
+		next, ok := p.keys.next(t.key)
+		if !ok {
+			return
+		}
+
+		// Waiting tasks are accounted for once they leave the key's queue.
+		span := p.startSubmit(&next)
+		p.stats.Submitted.Add(1)
+		handedOff := p.handOff(next)
+		span.End()
+
+		if handedOff {
+			return
+		}
+		t = next
+	}
+}
+
+// handOff dispatches a task without blocking and reports whether it was accepted.
+func (p *Pool) handOff(t task) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closedErr() != nil {
+		return false // Run the task in the calling goroutine, as Wait waits for it.
+	}
+
+	return p.dispatch(t)
+}
+
+// skipKeyed submits the next task waiting for key in place of a rejected one.
+func (p *Pool) skipKeyed(key string) {
+	next, ok := p.keys.next(key)
+	if !ok {
+		return
+	}
+
+	err := p.submit(context.Background(), next)
+	if errors.Is(err, ErrQueueFull) && p.errorHandler != nil {
+		p.errorHandler(err)
+	}
+}
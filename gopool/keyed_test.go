@@ -0,0 +1,138 @@
+package gopool_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPool_GoKeyed tests keyed serial execution in the gopool.Pool.
+func TestPool_GoKeyed(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs tasks with the same key serially in order", func(t *testing.T) {
+		t.Parallel()
+
+		const keys, tasksPerKey = 4, 100
+
+		var mu sync.Mutex
+		order := make(map[string][]int)
+		var running [keys]atomic.Int64
+		var overlapped atomic.Bool
+		pool := gopool.New(gopool.MaxGoroutines(3), gopool.QueueSize(2))
+
+		for i := 0; i < tasksPerKey; i++ {
+			for k := 0; k < keys; k++ {
+				key := strconv.Itoa(k)
+				pool.GoKeyed(key, func() error {
+					if running[k].Add(1) > 1 {
+						overlapped.Store(true)
+					}
+					defer running[k].Add(-1)
+
+					mu.Lock()
+					order[key] = append(order[key], i)
+					mu.Unlock()
+
+					return nil
+				})
+			}
+		}
+		pool.Wait()
+
+		require.False(t, overlapped.Load(), "Tasks with the same key should not overlap")
+		for k := 0; k < keys; k++ {
+			seq := order[strconv.Itoa(k)]
+			require.Len(t, seq, tasksPerKey)
+			for i, v := range seq {
+				require.Equal(t, i, v, "Tasks should run in submission order")
+			}
+		}
+		require.EqualValues(t, keys*tasksPerKey, pool.Stats().Submitted)
+	})
+
+	t.Run("does not block workers on a busy key", func(t *testing.T) {
+		t.Parallel()
+
+		var completed atomic.Int64
+		pool := gopool.New(gopool.MaxGoroutines(2))
+		release := make(chan struct{})
+
+		pool.GoKeyed("busy", func() error { <-release; return nil })
+		for i := 0; i < 5; i++ {
+			pool.GoKeyed("busy", func() error { completed.Add(1); return nil })
+		}
+
+		// The second worker keeps serving other keys while the busy key waits.
+		for i := 0; i < 5; i++ {
+			pool.GoKeyed("other"+strconv.Itoa(i), func() error { completed.Add(1); return nil })
+		}
+		require.Eventually(t, func() bool {
+			return completed.Load() == 5
+		}, time.Second, time.Millisecond, "Other keys should run while the busy key waits")
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 10, completed.Load())
+	})
+
+	t.Run("continues after a panic", func(t *testing.T) {
+		t.Parallel()
+
+		var panicked, completed atomic.Int64
+		pool := gopool.New(gopool.PanicHandler(func(any) { panicked.Add(1) }))
+
+		pool.GoKeyed("key", func() error { panic("test panic") })
+		pool.GoKeyed("key", func() error { completed.Add(1); return nil })
+		pool.Wait()
+
+		require.EqualValues(t, 1, panicked.Load())
+		require.EqualValues(t, 1, completed.Load(), "The next task of the key should run")
+	})
+
+	t.Run("passes over rejected tasks", func(t *testing.T) {
+		t.Parallel()
+
+		var completed, rejected atomic.Int64
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.RejectionPolicy(gopool.RejectDropOldest),
+			gopool.OnRejected(func(func(context.Context) error, error) { rejected.Add(1) }),
+		)
+		started, release := make(chan struct{}), make(chan struct{})
+		pool.Go(func() error { close(started); <-release; return nil })
+		<-started
+
+		pool.GoKeyed("key", func() error { completed.Add(1); return nil })  // Queued.
+		pool.GoKeyed("key", func() error { completed.Add(10); return nil }) // Waits for the key.
+		// Drops the queued keyed task, whose successor then drops this one.
+		pool.Go(func() error { completed.Add(100); return nil })
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 2, rejected.Load())
+		require.EqualValues(t, 10, completed.Load(), "The waiting task should run in place of the dropped one")
+	})
+
+	t.Run("reports tasks submitted after wait", func(t *testing.T) {
+		t.Parallel()
+
+		var rejected atomic.Int64
+		pool := gopool.New(gopool.OnRejected(func(func(context.Context) error, error) { rejected.Add(1) }))
+		pool.Wait()
+
+		pool.GoKeyed("key", func() error { return nil })
+		pool.GoKeyed("key", func() error { return nil })
+
+		require.EqualValues(t, 2, rejected.Load())
+	})
+}
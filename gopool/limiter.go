@@ -1,23 +1,176 @@
 package gopool
 
-// limiter is a simple channel-based limiter for controlling the number of goroutines.
-type limiter chan struct{}
+import (
+	"sync"
+)
 
-// release releases a permit from the limiter.
-func (l limiter) release() {
-	if l != nil {
-		<-l
+// limiter is a resizable weighted semaphore for controlling the number of goroutines.
+// Permits are granted to waiters in FIFO order, so a waiter for many permits is not
+// starved by waiters for few. A limit of zero means no limit.
+type limiter struct {
+	mu   sync.Mutex // mu protects all fields.
+	size int        // size is the maximum number of permits.
+	cur  int        // cur is the number of acquired permits.
+	head *waiter    // head is the first waiter in the queue.
+	tail *waiter    // tail is the last waiter in the queue.
+	free sync.Pool  // free recycles cancelled waiters, so that a blocking submission taken by a worker does not allocate.
+}
+
+// waiter is a pending request for permits.
+type waiter struct {
+	n       int           // n is the number of requested permits.
+	ready   chan struct{} // ready receives a single value once the permits are granted.
+	granted bool          // granted indicates if the permits were granted.
+	prev    *waiter       // prev is the previous waiter in the queue.
+	next    *waiter       // next is the next waiter in the queue.
+}
+
+// newLimiter creates a limiter with the given number of permits.
+func newLimiter(size int) *limiter {
+	return &limiter{ //nolint: exhaustruct
+		size: max(size, 0),
 	}
 }
 
-// limit returns the capacity of the limiter.
-func (l limiter) limit() int {
-	return cap(l)
+// limit returns the maximum number of permits, zero means no limit.
+func (l *limiter) limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.size
+}
+
+// used returns the number of acquired permits.
+func (l *limiter) used() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.cur
+}
+
+// setLimit changes the maximum number of permits. Growing the limit grants
+// permits to waiters; shrinking it takes effect as permits are released or shed.
+func (l *limiter) setLimit(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.size = max(size, 0)
+	l.notify()
+}
+
+// tryAcquire acquires a permit without blocking and reports whether it succeeded.
+func (l *limiter) tryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fits(1) && l.head == nil {
+		l.cur++
+
+		return true
+	}
+
+	return false
 }
 
-// close closes the limiter channel.
-func (l limiter) close() {
-	if l != nil {
-		close(l)
+// wait queues a request for n permits. The waiter's ready channel receives a value
+// once the permits are granted; the waiter must be cancelled if it is abandoned
+// without receiving it.
+func (l *limiter) wait(n int) *waiter {
+	w, _ := l.free.Get().(*waiter)
+	if w == nil {
+		w = &waiter{ready: make(chan struct{}, 1)} //nolint: exhaustruct
+	}
+	w.n, w.granted = n, false
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w.prev, w.next = l.tail, nil
+	if l.tail != nil {
+		l.tail.next = w
+	} else {
+		l.head = w
+	}
+	l.tail = w
+	l.notify()
+
+	return w
+}
+
+// cancel abandons a waiter. If the permits were already granted, they are released.
+// The waiter must not be used afterwards.
+func (l *limiter) cancel(w *waiter) {
+	l.mu.Lock()
+	if w.granted {
+		// The permits were granted in the meantime; give them back.
+		l.cur -= w.n
+		select {
+		case <-w.ready:
+		default:
+		}
+	} else {
+		l.remove(w)
+	}
+	l.notify()
+	l.mu.Unlock()
+
+	l.free.Put(w)
+}
+
+// release releases n permits.
+func (l *limiter) release(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cur -= n
+	l.notify()
+}
+
+// shed releases a permit only if more permits are acquired than the limit allows,
+// and reports whether it did so.
+func (l *limiter) shed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size > 0 && l.cur > l.size {
+		l.cur--
+
+		return true
+	}
+
+	return false
+}
+
+// fits reports whether n permits can be acquired. A request for more permits than
+// the limit is granted once no permits are acquired. The caller must hold mu.
+func (l *limiter) fits(n int) bool {
+	return l.size == 0 || l.cur+n <= l.size || l.cur == 0
+}
+
+// notify grants permits to queued waiters in order. The caller must hold mu.
+func (l *limiter) notify() {
+	for w := l.head; w != nil; w = l.head {
+		if !l.fits(w.n) {
+			return // Later waiters must not overtake the front one.
+		}
+		l.cur += w.n
+		l.remove(w)
+		w.granted = true
+		w.ready <- struct{}{}
+	}
+}
+
+// remove takes a waiter out of the queue. The caller must hold mu.
+func (l *limiter) remove(w *waiter) {
+	if w.prev != nil {
+		w.prev.next = w.next
+	} else {
+		l.head = w.next
+	}
+	if w.next != nil {
+		w.next.prev = w.prev
+	} else {
+		l.tail = w.prev
 	}
+	w.prev, w.next = nil, nil
 }
@@ -0,0 +1,70 @@
+package gopool //nolint: testpackage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Weighted(t *testing.T) {
+	t.Parallel()
+
+	// granted reports whether the permits of a waiter were granted.
+	granted := func(w *waiter) bool {
+		select {
+		case <-w.ready:
+			return true
+		default:
+			return false
+		}
+	}
+
+	t.Run("admits waiters in FIFO order", func(t *testing.T) {
+		t.Parallel()
+
+		l := newLimiter(4)
+		require.True(t, l.tryAcquire())
+		require.True(t, l.tryAcquire())
+
+		heavy := l.wait(4)
+		light := l.wait(1)
+		require.False(t, granted(heavy))
+		require.False(t, granted(light), "Light waiter should not overtake the heavy one")
+		require.False(t, l.tryAcquire(), "New permits should not overtake waiters")
+
+		l.release(1)
+		require.False(t, granted(heavy))
+		l.release(1)
+		require.True(t, granted(heavy))
+		require.False(t, granted(light))
+
+		l.release(4)
+		require.True(t, granted(light))
+		require.Equal(t, 1, l.used())
+	})
+
+	t.Run("grants oversized requests alone", func(t *testing.T) {
+		t.Parallel()
+
+		l := newLimiter(2)
+		require.True(t, l.tryAcquire())
+
+		w := l.wait(5)
+		require.False(t, granted(w))
+
+		l.release(1)
+		require.True(t, granted(w))
+		require.Equal(t, 5, l.used())
+	})
+
+	t.Run("returns granted permits on cancel", func(t *testing.T) {
+		t.Parallel()
+
+		l := newLimiter(3)
+		w := l.wait(3)
+		require.True(t, granted(w))
+
+		l.cancel(w)
+		require.Zero(t, l.used())
+	})
+}
@@ -2,7 +2,13 @@ package gopool
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"time"
+
+	"github.com/safeblock-dev/wr/internal/leak"
+	"github.com/safeblock-dev/wr/internal/ratelimit"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/safeblock-dev/wr/tracing"
 )
 
 // Option represents an option that can be passed when instantiating a Pool to customize it.
@@ -12,12 +18,14 @@ type Option func(pool *Pool)
 func Context(ctx context.Context) Option {
 	return func(pool *Pool) {
 		pool.parentCtx = ctx
-		pool.ctx, pool.cancelFunc = context.WithCancel(ctx)
+		pool.taskCtx, pool.taskCancel = context.WithCancel(ctx)
+		pool.ctx, pool.cancelFunc = context.WithCancel(pool.taskCtx)
 	}
 }
 
 // PanicHandler sets the panic handler function for the pool.
 // It allows customizing how panics are handled within the pool.
+// The handler receives a *syncgroup.PanicError carrying the stack of the panicking task.
 func PanicHandler(panicHandler func(any)) Option {
 	return func(pool *Pool) {
 		pool.panicHandler = panicHandler
@@ -32,25 +40,153 @@ func ErrorHandler(errorHandler func(err error)) Option {
 	}
 }
 
+// OnRejected sets a handler for tasks that will never run: tasks submitted after the pool
+// was closed or cancelled, and tasks rejected or discarded by the rejection policy.
+// The handler receives the task and the reason, one of ErrPoolClosed, ErrPoolCancelled,
+// ErrQueueFull or ErrTaskDropped, so it can requeue the task or account for it.
+// Tasks passed to TryGo, or whose GoContext context is done, are not reported.
+func OnRejected(handler RejectedHandler) Option {
+	return func(pool *Pool) {
+		pool.rejectedHandler = handler
+	}
+}
+
 // MaxGoroutines sets the maximum number of goroutines allowed in the pool.
 // It limits the number of concurrent tasks that can be executed simultaneously.
 func MaxGoroutines(limit int) Option {
 	return func(pool *Pool) {
 		if pool.limiter != nil {
-			pool.limiter.close()
-		}
-		switch {
-		case limit < 1:
-			pool.limiter = nil
-		default:
-			pool.limiter = make(limiter, limit)
+			pool.limiter.setLimit(limit)
+
+			return
 		}
+		pool.limiter = newLimiter(limit)
+	}
+}
+
+// TaskTimeout sets the maximum execution time of a task. Tasks submitted with GoCtx
+// receive a context with the corresponding deadline. A task that does not return in time
+// is abandoned: ErrTaskTimeout is passed to the error handler at the deadline, while the
+// task itself keeps running until it returns. Until then, it still occupies its worker's
+// slot, so MaxGoroutines keeps bounding the running tasks, and Wait waits for it.
+func TaskTimeout(timeout time.Duration) Option {
+	return func(pool *Pool) {
+		pool.taskTimeout = timeout
+	}
+}
+
+// Retry sets the policy for retrying failed tasks. Between attempts the pool waits
+// for an exponential backoff with jitter, giving up early when the pool is cancelled.
+// Only the error of the last attempt is passed to the error handler.
+func Retry(policy RetryPolicy) Option {
+	return func(pool *Pool) {
+		pool.retry = &policy
+	}
+}
+
+// RateLimit limits the rate at which tasks are started to rps tasks per second,
+// allowing bursts of up to burst tasks. Retry attempts count against the limit.
+// Workers wait for the rate limit until the pool is cancelled.
+func RateLimit(rps float64, burst int) Option {
+	return func(pool *Pool) {
+		pool.rateLimiter = ratelimit.New(rps, burst)
+	}
+}
+
+// IdleTimeout sets the time after which an idle worker exits and releases its goroutine slot.
+// By default workers live until Wait() is called.
+func IdleTimeout(timeout time.Duration) Option {
+	return func(pool *Pool) {
+		pool.idleTimeout = timeout
+	}
+}
+
+// MinGoroutines sets the number of workers started with the pool and kept alive
+// when idle, providing a warm floor of goroutines when IdleTimeout is used.
+// It is bounded by MaxGoroutines.
+func MinGoroutines(count int) Option {
+	return func(pool *Pool) {
+		pool.minWorkers = max(count, 0)
+	}
+}
+
+// QueueSize sets the capacity of the queue holding tasks that wait for a free worker.
+// It decouples submission from execution: when all goroutines are busy, tasks are
+// queued until the queue is full, at which point the rejection policy is applied.
+// The queue is only used together with MaxGoroutines.
+func QueueSize(size int) Option {
+	return func(pool *Pool) {
+		pool.queueSize = max(size, 0)
 	}
 }
 
-// defaultPanicHandler is the default panic handler that prints the panic information.
-// It logs the panic message with a distinctive error formatting.
-func defaultPanicHandler(pc any) {
-	const red = "\u001B[31m"
-	log.Printf("[%[1]sERROR%[1]s] %v", red, pc)
+// RejectionPolicy sets the policy applied to a task submitted while all goroutines
+// are busy and the queue is full. The default policy is RejectBlock.
+func RejectionPolicy(rejection Rejection) Option {
+	return func(pool *Pool) {
+		pool.rejection = rejection
+	}
+}
+
+// WorkerInit sets the function creating the state of a worker, such as a parser,
+// a database session or a scratch buffer, handed to tasks submitted with GoWithState.
+func WorkerInit(create func() (any, error)) Option {
+	return func(pool *Pool) {
+		pool.workerInit = create
+	}
+}
+
+// WorkerClose sets the function releasing the state of a worker when the worker exits.
+func WorkerClose(release func(state any)) Option {
+	return func(pool *Pool) {
+		pool.workerClose = release
+	}
+}
+
+// MiddlewareFunc wraps a task function, returning a function that calls next.
+type MiddlewareFunc func(next func() error) func() error
+
+// Middleware adds middleware wrapping every task run by the pool. The first middleware is
+// the outermost one. It is applied to every attempt of a retried task and runs within the
+// task's timeout, so it can add logging, metrics, tracing, recovery or timeouts in one place.
+func Middleware(middleware ...MiddlewareFunc) Option {
+	return func(pool *Pool) {
+		pool.middleware = append(pool.middleware, middleware...)
+	}
+}
+
+// Tracer sets the tracer of the pool. A submit span is started when a task is submitted,
+// as a child of the context passed to GoContext or of the pool's context, and an execute
+// span is started as its child when the task runs. Tasks submitted with GoCtx receive
+// a context carrying the execute span. By default tasks are not traced.
+func Tracer(tracer tracing.Tracer) Option {
+	return func(pool *Pool) {
+		pool.tracer = tracer
+	}
+}
+
+// Logger sets the logger of the pool. The default panic handler logs panics with it,
+// and task errors are logged with it unless an ErrorHandler is set.
+// By default panics are logged with the logger set by syncgroup.SetDefaultLogger
+// and task errors are not reported.
+func Logger(logger *slog.Logger) Option {
+	return func(pool *Pool) {
+		pool.logger = logger
+	}
+}
+
+// LeakDetector enables leak detection. If Wait has been blocked for longer than timeout,
+// report receives every task still running, with the call site of its submission,
+// its start time and its current stack. The wait is not interrupted.
+func LeakDetector(timeout time.Duration, report func(syncgroup.Report)) Option {
+	return func(pool *Pool) {
+		pool.leaks = leak.New(timeout, report)
+	}
+}
+
+// Name sets the name of the pool, added to its log records as the "pool" attribute.
+func Name(name string) Option {
+	return func(pool *Pool) {
+		pool.name = name
+	}
 }
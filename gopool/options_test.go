@@ -1,7 +1,9 @@
 package gopool //nolint: testpackage
 
 import (
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -15,34 +17,97 @@ func TestPool_MaxGoroutines(t *testing.T) {
 		t.Parallel()
 
 		pool := New(MaxGoroutines(maxConcurrent))
-		require.Equal(t, maxConcurrent, cap(pool.limiter), "limiter capacity should be set to maxConcurrent")
+		require.Equal(t, maxConcurrent, pool.limiter.limit(), "limiter limit should be set to maxConcurrent")
 	})
 
 	t.Run("should initialize limiter correctly after reset", func(t *testing.T) {
 		t.Parallel()
 
 		pool := New()
-		require.Nil(t, pool.limiter, "limiter should be nil initially")
+		require.Zero(t, pool.limiter.limit(), "limiter should be unlimited initially")
 
 		// Reconfigure the pool with a new limit
 		pool.Reset()
 		MaxGoroutines(maxConcurrent)(pool)
 
-		require.Equal(t, maxConcurrent, cap(pool.limiter), "limiter capacity should be set to maxConcurrent after reset")
+		require.Equal(t, maxConcurrent, pool.limiter.limit(), "limiter limit should be set to maxConcurrent after reset")
 	})
 
-	t.Run("should close old limiter channel", func(t *testing.T) {
+	t.Run("should update existing limiter", func(t *testing.T) {
 		t.Parallel()
 
 		pool := New(MaxGoroutines(maxConcurrent))
 		oldLimiter := pool.limiter
-		MaxGoroutines(maxConcurrent)(pool)
+		MaxGoroutines(maxConcurrent * 2)(pool)
+
+		require.Same(t, oldLimiter, pool.limiter, "limiter should be reused")
+		require.Equal(t, maxConcurrent*2, pool.limiter.limit(), "limiter limit should be updated")
+	})
+}
+
+func TestPool_IdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	const idleTimeout = 10 * time.Millisecond
 
-		select {
-		case _, ok := <-oldLimiter:
-			require.False(t, ok, "old limiter channel should be closed")
-		default:
-			require.Fail(t, "old limiter channel should be closed but it is not")
+	t.Run("should reap idle workers", func(t *testing.T) {
+		t.Parallel()
+
+		pool := New(MaxGoroutines(5), IdleTimeout(idleTimeout))
+		defer pool.Wait()
+
+		release := make(chan struct{})
+		for i := 0; i < 5; i++ {
+			pool.Go(func() error { <-release; return nil })
 		}
+		require.EqualValues(t, 5, pool.workers.Load())
+
+		close(release)
+		require.Eventually(t, func() bool {
+			return pool.workers.Load() == 0 && pool.limiter.used() == 0
+		}, time.Second, idleTimeout, "idle workers should exit and release their slots")
+
+		// The pool keeps working after the workers were reaped.
+		var completed atomic.Bool
+		pool.Go(func() error { completed.Store(true); return nil })
+		pool.Wait()
+		require.True(t, completed.Load())
+	})
+
+	t.Run("should keep minimum workers", func(t *testing.T) {
+		t.Parallel()
+
+		pool := New(MaxGoroutines(5), MinGoroutines(2), IdleTimeout(idleTimeout))
+		defer pool.Wait()
+
+		require.Eventually(t, func() bool {
+			return pool.workers.Load() == 2
+		}, time.Second, time.Millisecond, "minimum workers should be started with the pool")
+
+		release := make(chan struct{})
+		for i := 0; i < 5; i++ {
+			pool.Go(func() error { <-release; return nil })
+		}
+		close(release)
+
+		time.Sleep(5 * idleTimeout)
+		require.EqualValues(t, 2, pool.workers.Load(), "minimum workers should stay alive")
+	})
+
+	t.Run("should not lose queued tasks", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		pool := New(MaxGoroutines(2), QueueSize(10), IdleTimeout(time.Microsecond))
+
+		for i := 0; i < 1000; i++ {
+			pool.Go(func() error { counter.Add(1); return nil })
+			if i%100 == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		pool.Wait()
+
+		require.EqualValues(t, 1000, counter.Load())
 	})
 }
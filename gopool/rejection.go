@@ -0,0 +1,47 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrQueueFull is returned when a task is rejected by the RejectError policy.
+	ErrQueueFull = errors.New("gopool: task queue is full")
+
+	// ErrTaskDropped reports to the OnRejected handler that a task was discarded
+	// by the RejectDropNewest or RejectDropOldest policy.
+	ErrTaskDropped = errors.New("gopool: task dropped")
+
+	// ErrPoolClosed is returned when a task is submitted after Wait or Shutdown was called.
+	ErrPoolClosed = errors.New("gopool: pool is closed")
+
+	// ErrPoolCancelled is returned when a task is submitted after the pool's context was cancelled.
+	// It matches context.Canceled.
+	ErrPoolCancelled = fmt.Errorf("gopool: pool is cancelled: %w", context.Canceled)
+
+	// errCallerRuns reports that a task must be run by the submitter.
+	errCallerRuns = errors.New("gopool: caller runs task")
+)
+
+// Rejection is a policy applied to a task submitted while all goroutines
+// in the pool are busy and the task queue is full.
+type Rejection int
+
+const (
+	// RejectBlock blocks the submitter until the task can be queued.
+	RejectBlock Rejection = iota
+	// RejectDropNewest silently discards the submitted task.
+	RejectDropNewest
+	// RejectDropOldest discards the oldest queued task to make room for the submitted one.
+	// Without a queue it behaves like RejectDropNewest.
+	RejectDropOldest
+	// RejectCallerRuns runs the submitted task in the submitter's goroutine.
+	RejectCallerRuns
+	// RejectError rejects the submitted task with ErrQueueFull.
+	RejectError
+)
+
+// RejectedHandler receives a task that will never run and the reason it was rejected.
+type RejectedHandler func(f func(ctx context.Context) error, err error)
@@ -0,0 +1,260 @@
+package gopool_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPool_QueueSize tests task queueing of the gopool.Pool.
+func TestPool_QueueSize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("queues tasks without blocking", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		pool := gopool.New(gopool.MaxGoroutines(1), gopool.QueueSize(3))
+		started, release := make(chan struct{}), make(chan struct{})
+
+		pool.Go(func() error { close(started); <-release; counter.Add(1); return nil })
+		<-started
+		for i := 0; i < 3; i++ {
+			require.True(t, pool.TryGo(func() error { counter.Add(1); return nil }), "Task should be queued")
+		}
+		require.False(t, pool.TryGo(func() error { return nil }), "Task should be rejected when the queue is full")
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 4, counter.Load(), "Queued tasks should be executed")
+	})
+}
+
+// TestPool_RejectionPolicy tests the rejection policies of the gopool.Pool.
+func TestPool_RejectionPolicy(t *testing.T) {
+	t.Parallel()
+
+	// saturate fills the single worker and the queue of the pool.
+	saturate := func(pool *gopool.Pool, counter *atomic.Int64) chan struct{} {
+		started, release := make(chan struct{}), make(chan struct{})
+		pool.Go(func() error { close(started); <-release; counter.Add(1); return nil })
+		<-started
+		pool.Go(func() error { counter.Add(10); return nil })
+
+		return release
+	}
+
+	t.Run("drop newest", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.RejectionPolicy(gopool.RejectDropNewest),
+		)
+		release := saturate(pool, &counter)
+
+		require.NoError(t, pool.GoContext(context.Background(), func() error { counter.Add(100); return nil }))
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 11, counter.Load(), "Newest task should be dropped")
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.RejectionPolicy(gopool.RejectDropOldest),
+		)
+		release := saturate(pool, &counter)
+
+		require.NoError(t, pool.GoContext(context.Background(), func() error { counter.Add(100); return nil }))
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 101, counter.Load(), "Oldest queued task should be dropped")
+	})
+
+	t.Run("caller runs", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		var panicHandled atomic.Bool
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.RejectionPolicy(gopool.RejectCallerRuns),
+			gopool.PanicHandler(func(any) { panicHandled.Store(true) }),
+		)
+		release := saturate(pool, &counter)
+
+		pool.Go(func() error { counter.Add(100); return nil })
+		require.EqualValues(t, 100, counter.Load(), "Task should run in the caller's goroutine")
+
+		require.NotPanics(t, func() {
+			pool.Go(func() error { panic("test panic") })
+		})
+		require.True(t, panicHandled.Load())
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 111, counter.Load())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		var handledErr atomic.Value
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.RejectionPolicy(gopool.RejectError),
+			gopool.ErrorHandler(func(err error) { handledErr.Store(err) }),
+		)
+		release := saturate(pool, &counter)
+
+		err := pool.GoContext(context.Background(), func() error { return nil })
+		require.ErrorIs(t, err, gopool.ErrQueueFull)
+
+		pool.Go(func() error { return nil })
+		require.Equal(t, gopool.ErrQueueFull, handledErr.Load(), "Rejection should be passed to the error handler")
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 11, counter.Load())
+	})
+}
+
+// TestPool_OnRejected tests the reporting of tasks that will never run.
+func TestPool_OnRejected(t *testing.T) {
+	t.Parallel()
+
+	// rejections collects the reasons passed to the OnRejected handler.
+	type rejections struct {
+		mu      sync.Mutex
+		reasons []error
+	}
+	handler := func(r *rejections) gopool.Option {
+		return gopool.OnRejected(func(_ func(context.Context) error, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.reasons = append(r.reasons, err)
+		})
+	}
+
+	t.Run("reports tasks submitted after wait", func(t *testing.T) {
+		t.Parallel()
+
+		var rejected rejections
+		pool := gopool.New(handler(&rejected))
+		pool.Wait()
+
+		require.NotPanics(t, func() {
+			pool.Go(func() error { return nil })
+		})
+		err := pool.GoContext(context.Background(), func() error { return nil })
+		require.ErrorIs(t, err, gopool.ErrPoolClosed)
+		require.False(t, pool.TryGo(func() error { return nil }))
+
+		require.Equal(t, []error{gopool.ErrPoolClosed, gopool.ErrPoolClosed}, rejected.reasons)
+	})
+
+	t.Run("reports tasks submitted after cancel", func(t *testing.T) {
+		t.Parallel()
+
+		var rejected rejections
+		var ran atomic.Bool
+		pool := gopool.New(handler(&rejected))
+		pool.Cancel()
+
+		pool.GoCtx(func(context.Context) error { ran.Store(true); return nil })
+		pool.Wait()
+
+		require.False(t, ran.Load())
+		require.Equal(t, []error{gopool.ErrPoolCancelled}, rejected.reasons)
+	})
+
+	t.Run("passes the task to the handler", func(t *testing.T) {
+		t.Parallel()
+
+		var requeued atomic.Int64
+		other := gopool.New()
+		pool := gopool.New(gopool.OnRejected(func(f func(context.Context) error, _ error) {
+			other.GoCtx(f)
+		}))
+		pool.Wait()
+
+		pool.Go(func() error { requeued.Add(1); return nil })
+		other.Wait()
+
+		require.EqualValues(t, 1, requeued.Load(), "Rejected task should be requeued")
+	})
+
+	t.Run("reports tasks discarded by the rejection policy", func(t *testing.T) {
+		t.Parallel()
+
+		var rejected rejections
+		var counter atomic.Int64
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.RejectionPolicy(gopool.RejectDropOldest),
+			handler(&rejected),
+		)
+		started, release := make(chan struct{}), make(chan struct{})
+		pool.Go(func() error { close(started); <-release; return nil })
+		<-started
+		pool.Go(func() error { counter.Add(1); return nil })
+		pool.Go(func() error { counter.Add(10); return nil })
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 10, counter.Load())
+		require.Equal(t, []error{gopool.ErrTaskDropped}, rejected.reasons)
+	})
+
+	t.Run("does not panic when submitting concurrently with wait", func(t *testing.T) {
+		t.Parallel()
+
+		var submitted, rejected atomic.Int64
+		pool := gopool.New(
+			gopool.MaxGoroutines(2),
+			gopool.QueueSize(4),
+			gopool.OnRejected(func(func(context.Context) error, error) { rejected.Add(1) }),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					submitted.Add(1)
+					pool.Go(func() error { return nil })
+				}
+			}()
+		}
+		pool.Wait()
+		wg.Wait()
+
+		stats := pool.Stats()
+		require.Equal(t, submitted.Load(), int64(stats.Completed)+rejected.Load(), //nolint: gosec
+			"Every task should either run or be rejected")
+	})
+}
@@ -0,0 +1,140 @@
+package gopool
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ResultPool is a Pool whose tasks return a value. The values of successful tasks
+// are collected and returned by Wait together with the errors of failed tasks.
+type ResultPool[T any] struct {
+	pool    *Pool        // pool is the underlying Pool instance.
+	mu      sync.Mutex   // mu protects results and errs.
+	results []result[T]  // results stores the values returned by successful tasks.
+	errs    []error      // errs stores the errors returned by failed tasks.
+	index   atomic.Int64 // index is the submission index of the next task.
+	ordered bool         // ordered indicates if results are returned in submission order.
+}
+
+// result is a value returned by a task along with its submission index.
+type result[T any] struct {
+	index int64
+	value T
+}
+
+// NewResultPool creates a new ResultPool with the provided options.
+func NewResultPool[T any](options ...Option) *ResultPool[T] {
+	return &ResultPool[T]{ //nolint: exhaustruct
+		pool: New(options...),
+	}
+}
+
+// Ordered makes Wait return results in the order in which tasks were submitted
+// instead of the order in which they completed. It must be called before any task is submitted.
+func (r *ResultPool[T]) Ordered() *ResultPool[T] {
+	r.ordered = true
+
+	return r
+}
+
+// Go submits a task to be run in the pool. If all goroutines in the pool
+// are busy, a call to Go() will block until the task can be started.
+// The error of a failed task is also passed to the pool's error handler.
+// With a retry policy, only the outcome of the last attempt is collected.
+// A panic in the task is collected as a *syncgroup.PanicError, and also passed
+// to the pool's panic handler.
+func (r *ResultPool[T]) Go(f func() (T, error)) {
+	index := r.index.Add(1)
+
+	// A timed-out attempt may still be running when the task completes,
+	// so each attempt stores its value only once it has returned successfully.
+	var value atomic.Pointer[T]
+	var started atomic.Bool
+	t := task{ //nolint: exhaustruct
+		run: func() error {
+			started.Store(true)
+			v, err := f()
+			if err == nil {
+				value.Store(&v)
+			}
+
+			return err
+		},
+		complete: func(err error) {
+			if !started.Load() {
+				return // Rejected tasks are not collected.
+			}
+			if err != nil {
+				r.recordErr(err)
+
+				return
+			}
+			r.record(index, *value.Load())
+		},
+	}
+
+	err := r.pool.submit(context.Background(), t)
+	if errors.Is(err, ErrQueueFull) && r.pool.errorHandler != nil {
+		r.pool.errorHandler(err)
+	}
+}
+
+// record stores the value of the successful task with the given submission index.
+func (r *ResultPool[T]) record(index int64, value T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, result[T]{index: index, value: value})
+}
+
+// recordErr stores the error of a failed task.
+func (r *ResultPool[T]) recordErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs = append(r.errs, err)
+}
+
+// Wait waits for all tasks to complete and returns the values of successful tasks
+// and the joined errors of failed or panicked tasks. Tasks that were never started
+// because the pool was cancelled contribute neither a value nor an error.
+func (r *ResultPool[T]) Wait() ([]T, error) {
+	r.pool.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ordered {
+		slices.SortFunc(r.results, func(a, b result[T]) int {
+			return cmp.Compare(a.index, b.index)
+		})
+	}
+
+	values := make([]T, len(r.results))
+	for i, res := range r.results {
+		values[i] = res.value
+	}
+
+	return values, errors.Join(r.errs...)
+}
+
+// Cancel cancels the pool's context.
+func (r *ResultPool[T]) Cancel() {
+	r.pool.Cancel()
+}
+
+// Reset reactivates the pool and discards collected results, allowing new tasks to be submitted.
+func (r *ResultPool[T]) Reset() {
+	r.pool.Reset()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = nil
+	r.errs = nil
+	r.index.Store(0)
+}
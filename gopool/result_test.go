@@ -0,0 +1,152 @@
+package gopool_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResultPool_Go tests result collection of the gopool.ResultPool.
+func TestResultPool_Go(t *testing.T) {
+	t.Parallel()
+
+	t.Run("collects results", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.NewResultPool[int](gopool.MaxGoroutines(3))
+		numTasks := 10
+
+		for i := 0; i < numTasks; i++ {
+			pool.Go(func() (int, error) {
+				return i, nil
+			})
+		}
+
+		results, err := pool.Wait()
+
+		require.NoError(t, err)
+		require.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, results)
+	})
+
+	t.Run("keeps submission order", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.NewResultPool[int](gopool.MaxGoroutines(5)).Ordered()
+		numTasks := 10
+
+		for i := 0; i < numTasks; i++ {
+			pool.Go(func() (int, error) {
+				// Later tasks finish first.
+				time.Sleep(time.Duration(numTasks-i) * time.Millisecond)
+				return i, nil
+			})
+		}
+
+		results, err := pool.Wait()
+
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, results)
+	})
+
+	t.Run("collects errors", func(t *testing.T) {
+		t.Parallel()
+
+		var handled int
+		pool := gopool.NewResultPool[int](gopool.MaxGoroutines(1), gopool.ErrorHandler(func(_ error) {
+			handled++
+		}))
+		expectedError := errors.New("task error")
+
+		pool.Go(func() (int, error) { return 1, nil })
+		pool.Go(func() (int, error) { return 2, expectedError })
+
+		results, err := pool.Wait()
+
+		require.ErrorIs(t, err, expectedError)
+		require.Equal(t, []int{1}, results)
+		require.Equal(t, 1, handled, "Error handler should still be called")
+	})
+
+	t.Run("collects only the last attempt of a retried task", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.NewResultPool[int](gopool.Retry(gopool.RetryPolicy{MaxAttempts: 3}))
+		flaky := errors.New("flaky")
+		expectedError := errors.New("task error")
+
+		var attempts atomic.Int64
+		pool.Go(func() (int, error) {
+			if attempts.Add(1) < 3 {
+				return 0, flaky
+			}
+
+			return 42, nil
+		})
+		pool.Go(func() (int, error) { return 0, expectedError })
+
+		results, err := pool.Wait()
+
+		require.Equal(t, []int{42}, results)
+		require.ErrorIs(t, err, expectedError)
+		require.NotErrorIs(t, err, flaky, "Errors of failed attempts should not be collected")
+		require.Equal(t, expectedError.Error(), err.Error(), "A failed task should be collected once")
+	})
+
+	t.Run("collects panics as errors", func(t *testing.T) {
+		t.Parallel()
+
+		var panicHandled atomic.Bool
+		pool := gopool.NewResultPool[int](gopool.PanicHandler(func(any) { panicHandled.Store(true) }))
+
+		pool.Go(func() (int, error) { return 1, nil })
+		pool.Go(func() (int, error) { panic("test panic") })
+
+		results, err := pool.Wait()
+
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, err, &pe)
+		require.Equal(t, "test panic", pe.Value)
+		require.Equal(t, []int{1}, results)
+		require.True(t, panicHandled.Load(), "The panic handler should still be called")
+	})
+
+	t.Run("collects the timeout of a task still running", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.NewResultPool[int](gopool.TaskTimeout(5 * time.Millisecond))
+
+		pool.Go(func() (int, error) {
+			time.Sleep(30 * time.Millisecond)
+
+			return 1, nil
+		})
+		pool.Go(func() (int, error) { return 2, nil })
+
+		results, err := pool.Wait()
+
+		require.ErrorIs(t, err, gopool.ErrTaskTimeout)
+		require.Equal(t, []int{2}, results, "The value of a timed-out task should not be collected")
+	})
+}
+
+// TestResultPool_Reset tests the Reset method of the gopool.ResultPool.
+func TestResultPool_Reset(t *testing.T) {
+	t.Parallel()
+
+	pool := gopool.NewResultPool[string]()
+	pool.Go(func() (string, error) { return "first", nil })
+	results, err := pool.Wait()
+	require.NoError(t, err)
+	require.Equal(t, []string{"first"}, results)
+
+	pool.Reset()
+	pool.Go(func() (string, error) { return "second", nil })
+	results, err = pool.Wait()
+	require.NoError(t, err)
+	require.Equal(t, []string{"second"}, results)
+}
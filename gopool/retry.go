@@ -0,0 +1,76 @@
+package gopool
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy describes how failed tasks are retried by the pool.
+type RetryPolicy struct {
+	MaxAttempts int                  // MaxAttempts is the total number of attempts, including the first one.
+	BaseDelay   time.Duration        // BaseDelay is the delay before the first retry, doubled for every further retry.
+	MaxDelay    time.Duration        // MaxDelay caps the delay between attempts, zero means no cap.
+	Jitter      float64              // Jitter is the fraction of the delay, between 0 and 1, that is randomized.
+	Retryable   func(err error) bool // Retryable reports whether an error should be retried, nil retries all errors.
+}
+
+// retryable reports whether a failed attempt should be followed by another one.
+func (r *RetryPolicy) retryable(attempt int, err error) bool {
+	return attempt < r.MaxAttempts && (r.Retryable == nil || r.Retryable(err))
+}
+
+// delay returns the backoff before the attempt following the given one.
+func (r *RetryPolicy) delay(attempt int) time.Duration {
+	delay := r.BaseDelay
+	for i := 1; i < attempt; i++ {
+		if (r.MaxDelay > 0 && delay >= r.MaxDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+	if r.MaxDelay > 0 {
+		delay = min(delay, r.MaxDelay)
+	}
+
+	if jitter := min(max(r.Jitter, 0), 1); jitter > 0 {
+		delay -= time.Duration(jitter * rand.Float64() * float64(delay)) //nolint: gosec
+	}
+
+	return delay
+}
+
+// wait sleeps for the backoff following the given attempt.
+// It reports false if ctx is done before the backoff has elapsed.
+func (r *RetryPolicy) wait(ctx context.Context, attempt int) bool {
+	timer := time.NewTimer(r.delay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// callRetry runs a task with ctx, retrying it according to the pool's retry policy.
+// Only the error of the last attempt is returned. Panics are not retried. An attempt
+// that timed out is retried only once it has returned, as it still holds the slot.
+func (p *Pool) callRetry(ctx context.Context, t task, calls *timedCalls) error {
+	err := p.call(ctx, t, calls)
+	if p.retry == nil {
+		return err
+	}
+
+	for attempt := 1; err != nil && p.retry.retryable(attempt, err); attempt++ {
+		if !p.retry.wait(p.taskCtx, attempt) || !p.rateLimiter.Wait(p.taskCtx) {
+			break // The pool was cancelled during the backoff.
+		}
+		calls.wait()
+		err = p.call(ctx, t, calls)
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+package gopool_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPool_Retry tests retrying of failed tasks in the gopool.Pool.
+func TestPool_Retry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries until success", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts, errCount atomic.Int64
+		pool := gopool.New(
+			gopool.Retry(gopool.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, Jitter: 0.5}),
+			gopool.ErrorHandler(func(_ error) { errCount.Add(1) }),
+		)
+
+		pool.Go(func() error {
+			if attempts.Add(1) < 3 {
+				return errors.New("flaky error")
+			}
+
+			return nil
+		})
+		pool.Wait()
+
+		require.EqualValues(t, 3, attempts.Load())
+		require.Zero(t, errCount.Load(), "Intermediate errors should not reach the error handler")
+	})
+
+	t.Run("reports only the last error", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int64
+		var handled []error
+		pool := gopool.New(
+			gopool.Retry(gopool.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}),
+			gopool.ErrorHandler(func(err error) { handled = append(handled, err) }),
+		)
+
+		pool.Go(func() error {
+			return errors.New(time.Duration(attempts.Add(1)).String())
+		})
+		pool.Wait()
+
+		require.EqualValues(t, 3, attempts.Load())
+		require.Len(t, handled, 1)
+		require.EqualError(t, handled[0], "3ns")
+	})
+
+	t.Run("skips non retryable errors", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int64
+		permanent := errors.New("permanent error")
+		pool := gopool.New(gopool.Retry(gopool.RetryPolicy{
+			MaxAttempts: 5,
+			Retryable:   func(err error) bool { return !errors.Is(err, permanent) },
+		}))
+
+		pool.Go(func() error { attempts.Add(1); return permanent })
+		pool.Wait()
+
+		require.EqualValues(t, 1, attempts.Load())
+	})
+
+	t.Run("stops backoff on cancel", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int64
+		pool := gopool.New(gopool.Retry(gopool.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}))
+		failed := make(chan struct{})
+
+		pool.Go(func() error {
+			if attempts.Add(1) == 1 {
+				close(failed)
+			}
+
+			return errors.New("task error")
+		})
+
+		<-failed
+		pool.Cancel()
+		pool.Wait()
+
+		require.EqualValues(t, 1, attempts.Load())
+	})
+}
@@ -0,0 +1,22 @@
+package gopool
+
+import (
+	"fmt"
+)
+
+// ShutdownError is returned by Shutdown when its context is done before all tasks complete.
+type ShutdownError struct {
+	Running int64 // Running is the number of tasks that were still being executed.
+	Queued  int64 // Queued is the number of tasks that were still waiting for a worker.
+	Err     error // Err is the error of the shutdown context.
+}
+
+// Error returns a description of the unfinished shutdown.
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("gopool: shutdown: %v: %d tasks running, %d tasks queued", e.Err, e.Running, e.Queued)
+}
+
+// Unwrap returns the error of the shutdown context.
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
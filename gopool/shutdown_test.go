@@ -0,0 +1,76 @@
+package gopool_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPool_Shutdown tests the graceful shutdown of the gopool.Pool.
+func TestPool_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("drains queued and running tasks", func(t *testing.T) {
+		t.Parallel()
+
+		var counter atomic.Int64
+		pool := gopool.New(gopool.MaxGoroutines(1), gopool.QueueSize(5))
+
+		for i := 0; i < 5; i++ {
+			pool.Go(func() error { time.Sleep(time.Millisecond); counter.Add(1); return nil })
+		}
+
+		require.NoError(t, pool.Shutdown(context.Background()))
+		require.EqualValues(t, 5, counter.Load())
+		require.False(t, pool.TryGo(func() error { return nil }), "Pool should not accept tasks after shutdown")
+	})
+
+	t.Run("reports tasks in flight on deadline", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(1), gopool.QueueSize(2))
+		started := make(chan struct{})
+
+		pool.GoCtx(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		})
+		<-started
+		pool.Go(func() error { return nil })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := pool.Shutdown(ctx)
+
+		var shutdownErr *gopool.ShutdownError
+		require.ErrorAs(t, err, &shutdownErr)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.EqualValues(t, 1, shutdownErr.Running)
+		require.EqualValues(t, 1, shutdownErr.Queued)
+
+		// The running task is cancelled and the pool finishes stopping.
+		pool.Wait()
+	})
+
+	t.Run("concurrent wait blocks until stopped", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New()
+		release := make(chan struct{})
+		var completed atomic.Bool
+		pool.Go(func() error { <-release; completed.Store(true); return nil })
+
+		go pool.Wait()
+		time.Sleep(time.Millisecond)
+		close(release)
+		pool.Wait()
+
+		require.True(t, completed.Load())
+		require.NoError(t, pool.Shutdown(context.Background()), "Shutdown of a stopped pool should succeed")
+	})
+}
@@ -0,0 +1,75 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+)
+
+// workerState is the state owned by a worker, created by the WorkerInit function
+// before the first task submitted with GoWithState and closed when the worker exits.
+type workerState struct {
+	value any  // value is the state returned by the WorkerInit function.
+	ready bool // ready indicates if the state has been created.
+}
+
+// GoWithState submits a task to be run in the pool, handing it the state of the worker
+// running it. The state is created by the WorkerInit function when the worker runs its first
+// such task, and closed by the WorkerClose function when the worker exits, so each worker
+// owns one resource for its lifetime. If WorkerInit fails, the task fails with its error
+// and the state is created again for the next task. If the task times out, the state is
+// closed once the task returns and created again for the next task. Without WorkerInit,
+// the state is nil.
+// A task run by the caller, as with RejectCallerRuns, gets a state of its own.
+func (p *Pool) GoWithState(f func(state any) error) {
+	err := p.submit(context.Background(), task{runState: f}) //nolint: exhaustruct
+	if errors.Is(err, ErrQueueFull) && p.errorHandler != nil {
+		p.errorHandler(err)
+	}
+}
+
+// bind hands state to a task submitted with GoWithState.
+func (p *Pool) bind(t task, state *workerState) task {
+	if t.runState == nil {
+		return t
+	}
+
+	runState := t.runState
+	t.runState = nil
+	t.state = state
+	t.run = func() error {
+		if err := p.initState(state); err != nil {
+			return err
+		}
+
+		return runState(state.value)
+	}
+
+	return t
+}
+
+// initState creates the state of a worker unless it already exists.
+func (p *Pool) initState(state *workerState) error {
+	if state.ready || p.workerInit == nil {
+		return nil
+	}
+
+	value, err := p.workerInit()
+	if err != nil {
+		return err
+	}
+	state.value, state.ready = value, true
+
+	return nil
+}
+
+// closeState closes the state of a worker if it was created.
+func (p *Pool) closeState(state *workerState) {
+	if !state.ready {
+		return
+	}
+
+	state.ready = false
+	if p.workerClose != nil {
+		p.workerClose(state.value)
+	}
+}
@@ -0,0 +1,154 @@
+package gopool_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPool_GoWithState tests per-worker state in the gopool.Pool.
+func TestPool_GoWithState(t *testing.T) {
+	t.Parallel()
+
+	t.Run("creates one state per worker", func(t *testing.T) {
+		t.Parallel()
+
+		var created, closed atomic.Int64
+		var mu sync.Mutex
+		inUse := make(map[*int]bool)
+		var shared atomic.Bool
+		pool := gopool.New(
+			gopool.MaxGoroutines(3),
+			gopool.WorkerInit(func() (any, error) {
+				created.Add(1)
+
+				return new(int), nil
+			}),
+			gopool.WorkerClose(func(any) { closed.Add(1) }),
+		)
+
+		for i := 0; i < 100; i++ {
+			pool.GoWithState(func(state any) error {
+				counter := state.(*int) //nolint: forcetypeassert
+
+				mu.Lock()
+				if inUse[counter] {
+					shared.Store(true)
+				}
+				inUse[counter] = true
+				mu.Unlock()
+
+				*counter++
+
+				mu.Lock()
+				inUse[counter] = false
+				mu.Unlock()
+
+				return nil
+			})
+		}
+		pool.Wait()
+
+		require.False(t, shared.Load(), "A state should not be used by two tasks at once")
+		require.LessOrEqual(t, created.Load(), int64(3), "Each worker should create a single state")
+		require.Equal(t, created.Load(), closed.Load(), "Every state should be closed")
+		total := 0
+		for counter := range inUse {
+			total += *counter
+		}
+		require.Equal(t, 100, total)
+	})
+
+	t.Run("fails the task when the state cannot be created", func(t *testing.T) {
+		t.Parallel()
+
+		expectedError := errors.New("init error")
+		var attempts atomic.Int64
+		var handledErr atomic.Value
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.WorkerInit(func() (any, error) {
+				if attempts.Add(1) == 1 {
+					return nil, expectedError
+				}
+
+				return "state", nil
+			}),
+			gopool.ErrorHandler(func(err error) { handledErr.Store(err) }),
+		)
+
+		var states []any
+		pool.GoWithState(func(state any) error { states = append(states, state); return nil })
+		pool.GoWithState(func(state any) error { states = append(states, state); return nil })
+		pool.Wait()
+
+		require.Equal(t, expectedError, handledErr.Load())
+		require.Equal(t, []any{"state"}, states, "The state should be created again for the next task")
+	})
+
+	t.Run("hands a nil state without WorkerInit", func(t *testing.T) {
+		t.Parallel()
+
+		var called atomic.Bool
+		pool := gopool.New()
+
+		pool.GoWithState(func(state any) error {
+			called.Store(state == nil)
+
+			return nil
+		})
+		pool.Wait()
+
+		require.True(t, called.Load())
+	})
+
+	t.Run("closes the state of a timed-out task once it returns", func(t *testing.T) {
+		t.Parallel()
+
+		const timeout = 10 * time.Millisecond
+
+		type resource struct{ closed atomic.Bool }
+		var created, closed atomic.Int64
+		var usedClosed atomic.Bool
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.TaskTimeout(timeout),
+			gopool.WorkerInit(func() (any, error) {
+				created.Add(1)
+
+				return new(resource), nil
+			}),
+			gopool.WorkerClose(func(state any) {
+				state.(*resource).closed.Store(true) //nolint: forcetypeassert
+				closed.Add(1)
+			}),
+		)
+
+		var states [2]*resource
+		pool.GoWithState(func(state any) error {
+			states[0] = state.(*resource) //nolint: forcetypeassert
+			time.Sleep(3 * timeout)
+			usedClosed.Store(states[0].closed.Load())
+
+			return nil
+		})
+		pool.GoWithState(func(state any) error {
+			states[1] = state.(*resource) //nolint: forcetypeassert
+			usedClosed.CompareAndSwap(false, states[1].closed.Load())
+
+			return nil
+		})
+		pool.Wait()
+
+		require.False(t, usedClosed.Load(), "A state should not be closed while a task uses it")
+		require.NotSame(t, states[0], states[1], "The next task should get a new state")
+		require.EqualValues(t, 2, created.Load())
+		require.EqualValues(t, 2, closed.Load(), "Every state should be closed")
+	})
+}
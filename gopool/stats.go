@@ -0,0 +1,8 @@
+package gopool
+
+import (
+	"github.com/safeblock-dev/wr/internal/taskstats"
+)
+
+// Stats is a snapshot of the activity of a pool.
+type Stats = taskstats.Stats
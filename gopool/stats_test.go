@@ -0,0 +1,67 @@
+package gopool_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPool_Stats tests the statistics of the gopool.Pool.
+func TestPool_Stats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("counts finished tasks", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(2), gopool.PanicHandler(func(any) {}))
+
+		pool.Go(func() error { time.Sleep(time.Millisecond); return nil })
+		pool.Go(func() error { return errors.New("task error") })
+		pool.Go(func() error { panic("test panic") })
+		pool.Wait()
+
+		stats := pool.Stats()
+		require.EqualValues(t, 3, stats.Submitted)
+		require.EqualValues(t, 2, stats.Completed)
+		require.EqualValues(t, 1, stats.Failed)
+		require.EqualValues(t, 1, stats.Panicked)
+		require.Zero(t, stats.Running)
+		require.Zero(t, stats.Workers)
+		require.GreaterOrEqual(t, stats.MaxDuration, time.Millisecond)
+		require.Positive(t, stats.AvgDuration)
+		require.LessOrEqual(t, stats.AvgDuration, stats.MaxDuration)
+	})
+
+	t.Run("reports running and queued tasks", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(2),
+			gopool.RejectionPolicy(gopool.RejectDropNewest),
+		)
+		started, release := make(chan struct{}), make(chan struct{})
+
+		pool.Go(func() error { close(started); <-release; return nil })
+		<-started
+		pool.Go(func() error { return nil })
+		pool.Go(func() error { return nil })
+		pool.Go(func() error { return nil }) // Dropped.
+
+		stats := pool.Stats()
+		require.EqualValues(t, 1, stats.Workers)
+		require.EqualValues(t, 1, stats.Running)
+		require.Zero(t, stats.Idle)
+		require.EqualValues(t, 2, stats.Queued)
+		require.EqualValues(t, 3, stats.Submitted)
+		require.EqualValues(t, 1, stats.Rejected)
+
+		close(release)
+		pool.Wait()
+
+		require.EqualValues(t, 3, pool.Stats().Completed)
+	})
+}
@@ -0,0 +1,203 @@
+package gopool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/safeblock-dev/wr/syncgroup"
+)
+
+// ErrTaskTimeout is reported to the error handler when a task exceeds its timeout.
+var ErrTaskTimeout = errors.New("gopool: task timed out")
+
+// task is a unit of work submitted to the pool.
+type task struct {
+	run      func() error                    // run is the task function, if it does not accept a context.
+	runCtx   func(ctx context.Context) error // runCtx is the task function, if it accepts a context.
+	runState func(state any) error           // runState is the task function, if it accepts the worker's state.
+	timeout  time.Duration                   // timeout overrides the pool's task timeout when positive.
+	ctx      context.Context                 // ctx carries the submitter's values and the submit span.
+	key      string                          // key is the key of a task submitted with GoKeyed.
+	keyed    bool                            // keyed indicates if the task runs serially with tasks of the same key.
+	retired  chan struct{}                   // retired, if set, asks the receiving worker to exit and is closed once it did.
+	complete func(err error)                 // complete, if set, receives the final outcome of the task.
+	skip     func() bool                     // skip, if set, reports whether the task was cancelled before it started.
+	id       uint64                          // id is the submission number of the task within the pool.
+	site     string                          // site is the call site of the task's submission, with leak detection.
+	state    *workerState                    // state is the worker state handed to a task submitted with GoWithState.
+}
+
+// panicError wraps a value recovered from a panic in the task, recording the task's ID.
+// It must be called in the deferred function that recovered the panic.
+func (t task) panicError(pc any) *syncgroup.PanicError {
+	pe := syncgroup.NewPanicError(pc)
+	if pe.TaskID == 0 {
+		pe.TaskID = t.id
+	}
+
+	return pe
+}
+
+// invoke calls the task function with the given context.
+func (t task) invoke(ctx context.Context) error {
+	if t.runCtx != nil {
+		return t.runCtx(ctx)
+	}
+
+	return t.run()
+}
+
+// finish passes the final outcome of a task to its completion hook, if any.
+func (t task) finish(err error) {
+	if t.complete != nil {
+		t.complete(err)
+	}
+}
+
+// invoke calls the task function with the given context through the middleware chain.
+func (p *Pool) invoke(ctx context.Context, t task) error {
+	if len(p.middleware) == 0 {
+		return t.invoke(ctx)
+	}
+
+	next := func() error { return t.invoke(ctx) }
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		next = p.middleware[i](next)
+	}
+
+	return next()
+}
+
+// ctxFunc returns the task function as a function accepting a context.
+// A task submitted with GoWithState gets a state of its own.
+func (p *Pool) ctxFunc(t task) func(ctx context.Context) error {
+	if t.runCtx != nil {
+		return t.runCtx
+	}
+	if t.runState != nil {
+		return func(context.Context) error {
+			var state workerState
+			defer p.closeState(&state)
+
+			return p.bind(t, &state).run()
+		}
+	}
+
+	run := t.run
+
+	return func(context.Context) error { return run() }
+}
+
+// States of a timedCall.
+const (
+	callRunning int32 = iota
+	callFinished
+	callAbandoned
+)
+
+// timedCall is a task running in its own goroutine under a timeout.
+type timedCall struct {
+	state atomic.Int32  // state is one of callRunning, callFinished or callAbandoned.
+	done  chan struct{} // done is closed when the task finishes before being abandoned.
+	err   error         // err is the error returned by the task.
+	pc    any           // pc is the value recovered from a panic in the task.
+}
+
+// timedCalls tracks the goroutines of the timed calls of a task until they return.
+// The zero value is ready to use; it allocates only once a call is timed.
+type timedCalls struct {
+	wg *sync.WaitGroup // wg is created by the first timed call.
+}
+
+// add registers a timed call and returns the WaitGroup it must be marked done in.
+func (c *timedCalls) add() *sync.WaitGroup {
+	if c.wg == nil {
+		c.wg = new(sync.WaitGroup)
+	}
+	c.wg.Add(1)
+
+	return c.wg
+}
+
+// wait waits for the timed calls to return.
+func (c *timedCalls) wait() {
+	if c.wg != nil {
+		c.wg.Wait()
+	}
+}
+
+// call runs a task with ctx, derived from the pool's task context, bounded by its timeout.
+// A call running in its own goroutine is added to calls until it returns.
+func (p *Pool) call(ctx context.Context, t task, calls *timedCalls) error {
+	timeout := p.taskTimeout
+	if t.timeout > 0 {
+		timeout = t.timeout
+	}
+	if timeout <= 0 {
+		return p.invoke(ctx, t)
+	}
+
+	return p.callTimeout(ctx, t, timeout, calls)
+}
+
+// callTimeout runs a task in its own goroutine with a deadline. If the task does not
+// return in time, it is abandoned: ErrTaskTimeout is returned right away, while the task
+// keeps running until it returns. The goroutine is tracked by calls, so that the caller
+// can hold its slot until then. A panic in an abandoned task is passed to the panic handler,
+// its error is discarded. The worker state of an abandoned task is closed once it returns,
+// since the task may have left it in use.
+func (p *Pool) callTimeout(ctx context.Context, t task, timeout time.Duration, calls *timedCalls) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	c := &timedCall{done: make(chan struct{})} //nolint: exhaustruct
+
+	wg := calls.add()
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		defer func() {
+			if pc := recover(); pc != nil {
+				// Capture the stack here, the worker re-panics from another goroutine.
+				c.pc = t.panicError(pc)
+			}
+			if c.state.CompareAndSwap(callRunning, callFinished) {
+				close(c.done)
+
+				return
+			}
+			// The worker has moved on; nobody else will handle the panic.
+			if t.state != nil {
+				p.closeState(t.state)
+			}
+			if c.pc != nil && p.panicHandler != nil {
+				p.panicHandler(c.pc)
+			}
+		}()
+
+		c.err = p.invoke(ctx, t)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+		if c.state.CompareAndSwap(callRunning, callAbandoned) {
+			return ErrTaskTimeout
+		}
+		<-c.done // The task finished just in time.
+	}
+
+	if c.pc != nil {
+		panic(c.pc) // Propagate the panic as if the task ran in the worker.
+	}
+	if c.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTaskTimeout, c.err)
+	}
+
+	return c.err
+}
@@ -0,0 +1,138 @@
+package gopool_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPool_TaskTimeout tests the task timeout of the gopool.Pool.
+func TestPool_TaskTimeout(t *testing.T) {
+	t.Parallel()
+
+	const timeout = 10 * time.Millisecond
+
+	t.Run("abandons hung task", func(t *testing.T) {
+		t.Parallel()
+
+		var handledErr atomic.Value
+		timedOut := make(chan struct{})
+		pool := gopool.New(
+			gopool.MaxGoroutines(1),
+			gopool.QueueSize(1),
+			gopool.TaskTimeout(timeout),
+			gopool.ErrorHandler(func(err error) { handledErr.Store(err); close(timedOut) }),
+		)
+		hang := make(chan struct{})
+
+		var returned atomic.Bool
+		pool.Go(func() error {
+			<-hang
+			time.Sleep(timeout)
+			returned.Store(true)
+
+			return nil
+		})
+
+		// The timeout is reported at the deadline, but the hung task keeps the only slot.
+		var completed atomic.Bool
+		pool.Go(func() error { completed.Store(true); return nil })
+		<-timedOut
+		require.ErrorIs(t, handledErr.Load().(error), gopool.ErrTaskTimeout) //nolint: forcetypeassert
+		require.False(t, completed.Load(), "The next task should wait for the hung task to return")
+
+		close(hang)
+		pool.Wait()
+
+		require.True(t, returned.Load(), "Wait should wait for the hung task to return")
+		require.True(t, completed.Load())
+	})
+
+	t.Run("hands deadline to task", func(t *testing.T) {
+		t.Parallel()
+
+		var handledErr atomic.Value
+		pool := gopool.New(
+			gopool.TaskTimeout(timeout),
+			gopool.ErrorHandler(func(err error) { handledErr.Store(err) }),
+		)
+
+		pool.GoCtx(func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			require.True(t, ok, "Task context should have a deadline")
+			<-ctx.Done()
+
+			return ctx.Err()
+		})
+		pool.Wait()
+
+		err, _ := handledErr.Load().(error)
+		require.ErrorIs(t, err, gopool.ErrTaskTimeout)
+	})
+
+	t.Run("overrides timeout per call", func(t *testing.T) {
+		t.Parallel()
+
+		var errCount atomic.Int64
+		pool := gopool.New(
+			gopool.TaskTimeout(timeout),
+			gopool.ErrorHandler(func(_ error) { errCount.Add(1) }),
+		)
+
+		pool.GoCtxTimeout(func(ctx context.Context) error {
+			select {
+			case <-time.After(5 * timeout):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, time.Second)
+		pool.Wait()
+
+		require.Zero(t, errCount.Load(), "Task should finish within the overridden timeout")
+	})
+
+	t.Run("keeps task errors and panics", func(t *testing.T) {
+		t.Parallel()
+
+		var handledErr atomic.Value
+		var panicHandled atomic.Bool
+		pool := gopool.New(
+			gopool.TaskTimeout(time.Second),
+			gopool.ErrorHandler(func(err error) { handledErr.Store(err) }),
+			gopool.PanicHandler(func(any) { panicHandled.Store(true) }),
+		)
+		expectedError := errors.New("task error")
+
+		pool.Go(func() error { return expectedError })
+		pool.Go(func() error { panic("test panic") })
+		pool.Wait()
+
+		require.Equal(t, expectedError, handledErr.Load())
+		require.True(t, panicHandled.Load())
+	})
+
+	t.Run("keeps the stack of a panicking task", func(t *testing.T) {
+		t.Parallel()
+
+		var recovered atomic.Value
+		pool := gopool.New(
+			gopool.TaskTimeout(time.Second),
+			gopool.PanicHandler(func(pc any) { recovered.Store(pc) }),
+		)
+
+		pool.Go(func() error { panic("test panic") })
+		pool.Wait()
+
+		pe, ok := recovered.Load().(*syncgroup.PanicError)
+		require.True(t, ok, "Panic handler should receive a *syncgroup.PanicError")
+		require.Equal(t, "test panic", pe.Value)
+		require.Contains(t, string(pe.Stack), "gopool_test.TestPool_TaskTimeout")
+	})
+}
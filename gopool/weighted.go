@@ -0,0 +1,95 @@
+package gopool
+
+import (
+	"math"
+)
+
+// GoWeighted submits a task that occupies weight goroutine slots of the pool while it runs,
+// so that expensive tasks count more against MaxGoroutines than cheap ones. It blocks until
+// enough slots are free. Waiting tasks are admitted in FIFO order, so a heavy task is not
+// starved by a stream of light ones, and idle workers give up their slots to it.
+// A weight above the limit occupies the whole pool; without a limit, the weight is ignored.
+// A task submitted after Wait() or Cancel() is passed to the OnRejected handler instead.
+func (p *Pool) GoWeighted(weight int64, f func() error) {
+	if weight <= 1 || p.limiter.limit() == 0 {
+		p.Go(f) // A worker occupies a single slot.
+
+		return
+	}
+
+	t := task{run: f} //nolint: exhaustruct
+	span := p.startSubmit(&t)
+	defer span.End()
+
+	if err := p.runWeighted(int(min(weight, math.MaxInt32)), t); err != nil {
+		span.RecordError(err)
+		p.rejected(t, err)
+
+		return
+	}
+	p.stats.Submitted.Add(1)
+}
+
+// runWeighted waits for n slots and runs the task in a new goroutine holding them.
+// While waiting, it asks idle workers one at a time to exit and return their slots.
+// It gives up when the pool is closed or cancelled.
+func (p *Pool) runWeighted(n int, t task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if err := p.closedErr(); err != nil {
+		return err
+	}
+
+	w := p.limiter.wait(n)
+	select {
+	case <-w.ready:
+		// The slots are free; no worker has to exit.
+		p.startWeighted(n, t)
+
+		return nil
+	default:
+	}
+
+	token := task{retired: make(chan struct{})} //nolint: exhaustruct
+	tokens := p.tasks
+	var retired chan struct{}
+
+	for {
+		select {
+		case <-w.ready:
+			p.startWeighted(n, t)
+
+			return nil
+		case tokens <- token:
+			// A worker will exit and return its slot; wait for it before asking another one.
+			tokens, retired = nil, token.retired
+		case <-retired:
+			token = task{retired: make(chan struct{})} //nolint: exhaustruct
+			tokens, retired = p.tasks, nil
+		case <-p.ctx.Done():
+			// Context was cancelled; return without running the task.
+			p.limiter.cancel(w)
+
+			return p.closedErr()
+		}
+	}
+}
+
+// startWeighted runs the task in a new goroutine holding its n slots.
+func (p *Pool) startWeighted(n int, t task) {
+	p.group.Go(func() {
+		defer p.releaseWeighted(n)
+		p.execute(t)
+	})
+}
+
+// releaseWeighted releases the n slots of a weighted task and spawns workers
+// for tasks queued in the meantime.
+func (p *Pool) releaseWeighted(n int) {
+	p.limiter.release(n)
+
+	for i := 0; i < n && len(p.tasks) > 0; i++ {
+		p.ensureWorker()
+	}
+}
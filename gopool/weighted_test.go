@@ -0,0 +1,154 @@
+package gopool_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPool_GoWeighted tests weighted tasks in the gopool.Pool.
+func TestPool_GoWeighted(t *testing.T) {
+	t.Parallel()
+
+	t.Run("counts weights against the limit", func(t *testing.T) {
+		t.Parallel()
+
+		const limit = 4
+		var used, maxUsed atomic.Int64
+		pool := gopool.New(gopool.MaxGoroutines(limit), gopool.QueueSize(8))
+
+		// track records the slots used by running tasks.
+		track := func(weight int64) func() error {
+			return func() error {
+				current := used.Add(weight)
+				for {
+					peak := maxUsed.Load()
+					if current <= peak || maxUsed.CompareAndSwap(peak, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				used.Add(-weight)
+
+				return nil
+			}
+		}
+
+		for i := 0; i < 20; i++ {
+			pool.Go(track(1))
+			pool.GoWeighted(3, track(3))
+		}
+		pool.Wait()
+
+		require.LessOrEqual(t, maxUsed.Load(), int64(limit), "Used slots should not exceed the limit")
+		require.EqualValues(t, 40, pool.Stats().Completed)
+	})
+
+	t.Run("takes the slots of idle workers", func(t *testing.T) {
+		t.Parallel()
+
+		for _, queueSize := range []int{0, 4} {
+			pool := gopool.New(gopool.MaxGoroutines(2), gopool.QueueSize(queueSize))
+			release := make(chan struct{})
+			pool.Go(func() error { <-release; return nil })
+			pool.Go(func() error { <-release; return nil })
+			close(release)
+			require.Eventually(t, func() bool {
+				return pool.Stats().Completed == 2
+			}, time.Second, time.Millisecond)
+
+			var completed atomic.Bool
+			pool.GoWeighted(2, func() error { completed.Store(true); return nil })
+			pool.Wait()
+
+			require.True(t, completed.Load(), "Weighted task should run once idle workers exit")
+		}
+	})
+
+	t.Run("is not starved by light tasks", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopool.New(gopool.MaxGoroutines(2))
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					pool.Go(func() error { time.Sleep(time.Millisecond); return nil })
+				}
+			}
+		}()
+
+		var completed atomic.Bool
+		time.Sleep(5 * time.Millisecond)
+		pool.GoWeighted(2, func() error { completed.Store(true); return nil })
+		require.Eventually(t, completed.Load, time.Second, time.Millisecond, "Weighted task should be admitted")
+
+		close(stop)
+		<-done
+		pool.Wait()
+	})
+
+	t.Run("does not deadlock when mixed with light tasks", func(t *testing.T) {
+		t.Parallel()
+
+		const iterations, submitters, tasksPerSubmitter = 300, 8, 10
+
+		for i := 0; i < iterations; i++ {
+			var completed atomic.Int64
+			pool := gopool.New(gopool.MaxGoroutines(3))
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+
+				var wg sync.WaitGroup
+				for s := 0; s < submitters; s++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for j := 0; j < tasksPerSubmitter; j++ {
+							if (s+j)%2 == 0 {
+								pool.GoWeighted(3, func() error { completed.Add(1); return nil })
+							} else {
+								pool.Go(func() error { completed.Add(1); return nil })
+							}
+						}
+					}()
+				}
+				wg.Wait()
+				pool.Wait()
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				require.FailNow(t, "The pool deadlocked", "iteration %d, stats %+v", i, pool.Stats())
+			}
+			require.EqualValues(t, submitters*tasksPerSubmitter, completed.Load())
+		}
+	})
+
+	t.Run("reports tasks submitted after wait", func(t *testing.T) {
+		t.Parallel()
+
+		var reason error
+		pool := gopool.New(
+			gopool.MaxGoroutines(2),
+			gopool.OnRejected(func(_ func(context.Context) error, err error) { reason = err }),
+		)
+		pool.Wait()
+
+		pool.GoWeighted(2, func() error { return nil })
+		require.ErrorIs(t, reason, gopool.ErrPoolClosed)
+	})
+}
@@ -1,11 +1,15 @@
 package gopoolch
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
-	"github.com/safeblock-dev/werr"
 	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/syncgroup"
 )
 
 // PoolCh is a wrapper around gopool.Pool with additional error handling.
@@ -14,6 +18,10 @@ type PoolCh struct {
 	err     error        // err stores the first error encountered in the pool.
 	errCh   chan error   // errCh is a channel for propagating errors from the pool.
 	errOnce sync.Once    // errOnce ensures the error is only set once.
+	mu      sync.Mutex   // mu protects errs.
+	errs    []error      // errs stores all errors encountered in the pool when they are collected.
+	collect bool         // collect indicates if all errors are collected instead of cancelling the pool.
+	cancel  func(error)  // cancel, if set, cancels the context returned by WithContext.
 	stopped atomic.Bool  // stopped indicates if the pool has been stopped.
 }
 
@@ -35,21 +43,83 @@ func New(options ...gopool.Option) *PoolCh {
 	return &p
 }
 
-// Go submits a task to the pool for execution.
+// WithContext creates a new PoolCh and a context derived from ctx, like errgroup.WithContext.
+// The context is cancelled with the error as its cause on the first error or panic in the pool,
+// or once Wait returns, whichever occurs first. It is not renewed by Reset.
+func WithContext(ctx context.Context, options ...gopool.Option) (*PoolCh, context.Context) {
+	derived, cancel := context.WithCancelCause(ctx)
+	p := New(append([]gopool.Option{gopool.Context(ctx)}, options...)...)
+	p.cancel = cancel
+
+	return p, derived
+}
+
+// CollectErrors makes the pool gather every error and panic instead of cancelling
+// the pool on the first one, so that all tasks run. The first error is still sent
+// to the error channel; all of them are returned by Errors.
+// It must be called before any task is submitted.
+func (p *PoolCh) CollectErrors() *PoolCh {
+	p.collect = true
+
+	return p
+}
+
+// Go submits a task to the pool for execution. A task submitted after Wait(),
+// or after the first error unless errors are collected, is not run;
+// it is passed to the OnRejected handler instead.
 func (p *PoolCh) Go(f func() error) {
-	if !p.stopped.Load() {
-		p.pool.Go(f)
-	}
+	p.pool.Go(f)
+}
+
+// GoCtx submits a task to the pool for execution, handing it the pool's context.
+// The context is cancelled on the first error or panic in the pool,
+// unless errors are collected.
+func (p *PoolCh) GoCtx(f func(ctx context.Context) error) {
+	p.pool.GoCtx(f)
+}
+
+// GoCtxTimeout submits a task to the pool for execution, handing it the pool's context
+// with a deadline that overrides the pool's TaskTimeout.
+func (p *PoolCh) GoCtxTimeout(f func(ctx context.Context) error, timeout time.Duration) {
+	p.pool.GoCtxTimeout(f, timeout)
+}
+
+// TryGo submits a task to the pool only if it can be started or queued immediately,
+// like errgroup.Group.TryGo. It reports whether the task was submitted.
+func (p *PoolCh) TryGo(f func() error) bool {
+	return p.pool.TryGo(f)
+}
+
+// SetLimit changes the maximum number of goroutines running tasks, like errgroup.Group.SetLimit.
+// A limit below 1 removes the limit: unlike errgroup, where a limit of zero lets no task run,
+// SetLimit(0) does not block the pool. Also unlike errgroup, it may be called while tasks are running.
+func (p *PoolCh) SetLimit(n int) {
+	p.pool.SetMaxGoroutines(n)
 }
 
 // Wait waits for all tasks in the pool to complete and closes the error channel.
 func (p *PoolCh) Wait() {
 	if p.stopped.CompareAndSwap(false, true) {
 		p.pool.Wait()
+		if p.cancel != nil {
+			p.cancel(p.err)
+		}
 		close(p.errCh)
 	}
 }
 
+// WaitErr waits for all tasks in the pool to complete, closes the error channel
+// and returns the pool's error, like errgroup.Group.Wait. When errors are collected,
+// all of them are returned joined. Unlike Hold, it does not reset the pool.
+func (p *PoolCh) WaitErr() error {
+	p.Wait()
+	if p.collect {
+		return errors.Join(p.Errors()...)
+	}
+
+	return p.Error()
+}
+
 // Reset reactivates the pool, allowing new tasks to be submitted.
 func (p *PoolCh) Reset() {
 	p.Wait()
@@ -58,16 +128,22 @@ func (p *PoolCh) Reset() {
 	p.err = nil
 	p.errCh = make(chan error, 1)
 	p.errOnce = sync.Once{}
+	p.mu.Lock()
+	p.errs = nil
+	p.mu.Unlock()
 }
 
+// Hold waits for all tasks, then resets the pool and returns its error.
+// When errors are collected, all of them are returned joined.
 func (p *PoolCh) Hold() error {
-	p.Wait()
 	defer p.Reset()
-	if p.HasError() {
-		return p.Error()
-	}
 
-	return nil
+	return p.WaitErr()
+}
+
+// Stats returns a snapshot of the pool's activity.
+func (p *PoolCh) Stats() gopool.Stats {
+	return p.pool.Stats()
 }
 
 // ErrorChannel returns a channel that can be used to receive errors that occur in the pool.
@@ -80,23 +156,51 @@ func (p *PoolCh) Error() error {
 	return p.err
 }
 
+// Errors returns all errors that occurred in the pool when errors are collected,
+// or the first error otherwise. The result can be combined with errors.Join.
+func (p *PoolCh) Errors() []error {
+	if !p.collect {
+		if p.err == nil {
+			return nil
+		}
+
+		return []error{p.err}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return slices.Clone(p.errs)
+}
+
 // HasError returns true if an error has occurred in the pool.
 func (p *PoolCh) HasError() bool {
 	return p.err != nil
 }
 
 // panicHandler handles panics that occur in the pool by converting
-// them to errors and passing them to the error handler.
+// them to *syncgroup.PanicError errors and passing them to the error handler.
 func (p *PoolCh) panicHandler(pc interface{}) {
-	p.errorHandler(werr.PanicToError(pc))
+	p.errorHandler(syncgroup.NewPanicError(pc))
 }
 
 // errorHandler handles errors that occur in the pool by sending
 // them to the error channel and setting the error.
 func (p *PoolCh) errorHandler(err error) {
+	if p.collect {
+		p.mu.Lock()
+		p.errs = append(p.errs, err)
+		p.mu.Unlock()
+	}
+
 	p.errOnce.Do(func() {
 		p.err = err
 		p.errCh <- err
-		p.pool.Cancel()
+		if !p.collect {
+			p.pool.Cancel()
+			if p.cancel != nil {
+				p.cancel(err)
+			}
+		}
 	})
 }
@@ -1,10 +1,14 @@
 package gopoolch_test
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 
+	"github.com/safeblock-dev/wr/gopool"
 	"github.com/safeblock-dev/wr/gopoolch"
+	"github.com/safeblock-dev/wr/syncgroup"
 	"github.com/stretchr/testify/require"
 )
 
@@ -81,9 +85,52 @@ func TestPoolCh_Go(t *testing.T) {
 		require.True(t, pool.HasError())
 		require.ErrorIs(t, pool.Error(), expectedPanic)
 		require.Equal(t, pool.Error(), <-pool.ErrorChannel())
+
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, pool.Error(), &pe)
+		require.Contains(t, string(pe.Stack), "gopoolch_test.TestPoolCh_Go")
 	})
 }
 
+func TestPoolCh_GoCtx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error cancels running tasks", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopoolch.New()
+		started := make(chan struct{})
+		expectedError := errors.New("task error")
+
+		pool.GoCtx(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		<-started
+		pool.GoCtx(func(_ context.Context) error {
+			return expectedError
+		})
+
+		pool.Wait()
+
+		require.Equal(t, expectedError, pool.Error())
+	})
+}
+
+func TestPoolCh_Stats(t *testing.T) {
+	t.Parallel()
+
+	pool := gopoolch.New()
+	pool.Go(func() error { return nil })
+	pool.Go(func() error { return errors.New("task error") })
+	pool.Wait()
+
+	stats := pool.Stats()
+	require.EqualValues(t, 2, stats.Completed)
+	require.EqualValues(t, 1, stats.Failed)
+}
+
 func TestPoolCh_Reset(t *testing.T) {
 	t.Parallel()
 
@@ -115,4 +162,215 @@ func TestPoolCh_GoAfterWait(t *testing.T) {
 			pool.Go(func() error { return nil })
 		})
 	})
+
+	t.Run("report rejected tasks", func(t *testing.T) {
+		t.Parallel()
+
+		var reasons []error
+		ctx, cancel := context.WithCancel(context.Background())
+		pool := gopoolch.New(gopool.Context(ctx), gopool.OnRejected(func(_ func(context.Context) error, err error) {
+			reasons = append(reasons, err)
+		}))
+		cancel()
+
+		pool.Go(func() error { return nil })
+		pool.Wait()
+		pool.Go(func() error { return nil })
+
+		require.Equal(t, []error{gopool.ErrPoolCancelled, gopool.ErrPoolClosed}, reasons)
+	})
+}
+
+func TestPoolCh_CollectErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("collects all errors without cancelling", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopoolch.New(gopool.MaxGoroutines(2)).CollectErrors()
+		errFirst, errSecond := errors.New("first error"), errors.New("second error")
+
+		var completed atomic.Int64
+		pool.Go(func() error { return errFirst })
+		pool.Go(func() error { return errSecond })
+		pool.Go(func() error { panic("test panic") })
+		for i := 0; i < 5; i++ {
+			pool.GoCtx(func(ctx context.Context) error {
+				if ctx.Err() == nil {
+					completed.Add(1)
+				}
+
+				return nil
+			})
+		}
+		pool.Wait()
+
+		errs := pool.Errors()
+		require.Len(t, errs, 3)
+		require.EqualValues(t, 5, completed.Load(), "Other tasks should not be cancelled")
+		joined := errors.Join(errs...)
+		require.ErrorIs(t, joined, errFirst)
+		require.ErrorIs(t, joined, errSecond)
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, joined, &pe)
+		require.Contains(t, errs, <-pool.ErrorChannel(), "The first error should be sent to the channel")
+	})
+
+	t.Run("returns the joined errors from Hold", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopoolch.New().CollectErrors()
+		errFirst, errSecond := errors.New("first error"), errors.New("second error")
+
+		pool.Go(func() error { return errFirst })
+		pool.Go(func() error { return errSecond })
+		err := pool.Hold()
+
+		require.ErrorIs(t, err, errFirst)
+		require.ErrorIs(t, err, errSecond)
+		require.Empty(t, pool.Errors(), "Hold should reset the collected errors")
+	})
+
+	t.Run("returns the first error without collecting", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopoolch.New()
+		expectedError := errors.New("task error")
+
+		pool.Go(func() error { return expectedError })
+		pool.Wait()
+
+		require.Equal(t, []error{expectedError}, pool.Errors())
+	})
+}
+
+// TestWithContext tests the context returned by gopoolch.WithContext and the errgroup-style methods.
+func TestWithContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cancels the context on the first error", func(t *testing.T) {
+		t.Parallel()
+
+		pool, ctx := gopoolch.WithContext(context.Background())
+		expectedError := errors.New("task error")
+
+		pool.Go(func() error {
+			<-ctx.Done() // The task captured the context instead of using GoCtx.
+
+			return nil
+		})
+		pool.Go(func() error { return expectedError })
+		pool.Wait()
+
+		require.Equal(t, expectedError, pool.Error())
+		require.ErrorIs(t, context.Cause(ctx), expectedError)
+	})
+
+	t.Run("cancels the context on a panic", func(t *testing.T) {
+		t.Parallel()
+
+		pool, ctx := gopoolch.WithContext(context.Background())
+
+		pool.Go(func() error { panic("test panic") })
+		<-ctx.Done()
+		pool.Wait()
+
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, context.Cause(ctx), &pe)
+	})
+
+	t.Run("cancels the context once Wait returns", func(t *testing.T) {
+		t.Parallel()
+
+		pool, ctx := gopoolch.WithContext(context.Background())
+
+		var taskErr error
+		pool.Go(func() error {
+			taskErr = ctx.Err()
+
+			return nil
+		})
+		pool.Wait()
+
+		require.NoError(t, taskErr, "The context should not be cancelled while tasks run")
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+		require.NoError(t, pool.Error())
+	})
+
+	t.Run("follows the parent context", func(t *testing.T) {
+		t.Parallel()
+
+		parent, cancel := context.WithCancel(context.Background())
+		pool, ctx := gopoolch.WithContext(parent)
+		defer pool.Wait()
+
+		cancel()
+
+		<-ctx.Done()
+		require.False(t, pool.TryGo(func() error { return nil }), "The pool should be cancelled")
+	})
+
+	t.Run("limits the number of goroutines", func(t *testing.T) {
+		t.Parallel()
+
+		pool, _ := gopoolch.WithContext(context.Background())
+		pool.SetLimit(1)
+
+		release := make(chan struct{})
+		require.True(t, pool.TryGo(func() error { <-release; return nil }))
+		require.False(t, pool.TryGo(func() error { return nil }), "The limit should be reached")
+
+		close(release)
+		pool.Wait()
+	})
+
+	t.Run("returns the error from WaitErr", func(t *testing.T) {
+		t.Parallel()
+
+		pool, _ := gopoolch.WithContext(context.Background())
+		expectedError := errors.New("task error")
+
+		pool.Go(func() error { return expectedError })
+
+		require.Equal(t, expectedError, pool.WaitErr())
+		require.Equal(t, expectedError, pool.WaitErr(), "The pool should not be reset")
+	})
+
+	t.Run("returns the joined errors from WaitErr", func(t *testing.T) {
+		t.Parallel()
+
+		pool := gopoolch.New(gopool.MaxGoroutines(1)).CollectErrors()
+		firstError := errors.New("first error")
+		secondError := errors.New("second error")
+
+		pool.Go(func() error { return firstError })
+		pool.Go(func() error { return secondError })
+		err := pool.WaitErr()
+
+		require.ErrorIs(t, err, firstError)
+		require.ErrorIs(t, err, secondError)
+	})
+
+	t.Run("returns nil from WaitErr without errors", func(t *testing.T) {
+		t.Parallel()
+
+		pool, _ := gopoolch.WithContext(context.Background())
+		pool.Go(func() error { return nil })
+
+		require.NoError(t, pool.WaitErr())
+	})
+
+	t.Run("removes the limit with a limit of zero", func(t *testing.T) {
+		t.Parallel()
+
+		pool, _ := gopoolch.WithContext(context.Background())
+		pool.SetLimit(0)
+
+		release := make(chan struct{})
+		require.True(t, pool.TryGo(func() error { <-release; return nil }))
+		require.True(t, pool.TryGo(func() error { <-release; return nil }), "Unlike errgroup, zero means no limit")
+
+		close(release)
+		require.NoError(t, pool.WaitErr())
+	})
 }
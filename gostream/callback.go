@@ -1,13 +1,16 @@
 package gostream
 
 import (
+	"context"
 	"sync"
 )
 
 // callbackData represents data associated with a callback, including the callback function and any error.
 type callbackData struct {
-	fn  func() error // fn is the callback function to execute.
-	err error        // err is any error that occurred during task execution.
+	fn  func() error    // fn is the callback function to execute.
+	err error           // err is any error that occurred during task execution.
+	ctx context.Context // ctx carries the submit span of the task.
+	id  uint64          // id is the submission number of the task.
 }
 
 // callbackChannel is a channel for sending callbackData.
@@ -2,35 +2,56 @@ package gostream
 
 import (
 	"context"
+	"log/slog"
+	"sync"
 	"sync/atomic"
 
 	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/internal/ratelimit"
+	"github.com/safeblock-dev/wr/internal/taskstats"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/safeblock-dev/wr/tracing"
 )
 
 // Stream manages the execution of tasks and their corresponding callbacks.
 type Stream struct {
-	ctx             context.Context      // ctx is the current context for the stream.
-	parentCtx       context.Context      // parentCtx is the parent context of the stream.
-	cancelFunc      context.CancelFunc   // cancelFunc cancels the stream context.
-	callbackQueueCh chan callbackChannel // callbackQueueCh is a channel for callback channels.
-	panicHandler    func(any)            // panicHandler handles panics that occur in tasks.
-	errorHandler    func(err error)      // errorHandler handles errors that occur in tasks.
-	workerPool      *gopool.Pool         // workerPool manages the goroutines executing tasks.
-	maxGoroutines   int                  // maxGoroutines is the maximum number of concurrent goroutines.
-	stopped         atomic.Bool          // stopped indicates if the stream has been stopped.
+	mu                 sync.RWMutex                   // mu prevents the callback queue from being closed while tasks are submitted.
+	ctx                context.Context                // ctx is the current context for the stream.
+	parentCtx          context.Context                // parentCtx is the parent context of the stream.
+	cancelFunc         context.CancelFunc             // cancelFunc cancels the stream context.
+	callbackQueueCh    chan callbackChannel           // callbackQueueCh is a channel for callback channels.
+	panicHandler       func(any)                      // panicHandler handles panics that occur in tasks.
+	errorHandler       func(err error)                // errorHandler handles errors that occur in tasks.
+	rejectedHandler    func(f TaskCtx, err error)     // rejectedHandler receives tasks that will never run.
+	workerPool         *gopool.Pool                   // workerPool manages the goroutines executing tasks.
+	maxGoroutines      int                            // maxGoroutines is the maximum number of concurrent goroutines.
+	rateLimiter        *ratelimit.Limiter             // rateLimiter limits the rate at which tasks are started.
+	taskMiddleware     []func(next Task) Task         // taskMiddleware wraps every task run by the stream.
+	callbackMiddleware []func(next Callback) Callback // callbackMiddleware wraps every callback run by the stream.
+	tracer             tracing.Tracer                 // tracer starts the spans of submitted and executed tasks.
+	logger             *slog.Logger                   // logger is the logger of the default panic and error handlers.
+	name               string                         // name is the name of the stream in log records.
+	taskIDs            atomic.Uint64                  // taskIDs is the number of the last submitted task.
+	stopped            atomic.Bool                    // stopped indicates if the stream has been stopped.
+	stats              taskstats.Counters             // stats collects the statistics of the stream.
 }
 
 // Task is a function that returns a Callback and an error.
 type Task func() (Callback, error)
 
+// TaskCtx is a Task that receives the stream's context.
+type TaskCtx func(ctx context.Context) (Callback, error)
+
 // Callback is a function that is executed after a Task completes.
 type Callback func() error
 
 // New creates a new Stream with the provided options.
 func New(options ...Option) *Stream {
 	stream := &Stream{ //nolint: exhaustruct
-		panicHandler: defaultPanicHandler,
+		tracer: tracing.Noop,
 	}
+	stream.panicHandler = stream.logPanic
+	stream.errorHandler = stream.logError
 
 	// Apply all options.
 	for _, opt := range options {
@@ -46,7 +67,10 @@ func New(options ...Option) *Stream {
 		stream.maxGoroutines++
 	}
 
-	stream.workerPool = gopool.New(gopool.MaxGoroutines(stream.maxGoroutines))
+	stream.workerPool = gopool.New(
+		gopool.MaxGoroutines(stream.maxGoroutines),
+		gopool.PanicHandler(stream.panicHandler),
+	)
 	stream.callbackQueueCh = make(chan callbackChannel, stream.maxGoroutines+1)
 
 	// Start the callback reader with panic protection.
@@ -55,37 +79,103 @@ func New(options ...Option) *Stream {
 	return stream
 }
 
-// Go submits a Task to the Stream for execution.
+// Go submits a Task to the Stream for execution. A task submitted after Wait()
+// or Cancel() is not run; it is passed to the OnRejected handler instead.
 func (s *Stream) Go(f Task) {
-	if s.ctx.Err() != nil {
-		return
+	task := func(context.Context) (Callback, error) { return f() }
+	if err := s.submit(task); err != nil {
+		s.rejected(task, err)
+	}
+}
+
+// GoCtx submits a TaskCtx to the Stream for execution, handing it the stream's context.
+// The context is cancelled by Cancel() or the parent context, so long-running tasks
+// can stop promptly. With a Tracer, the context carries the task's execute span.
+func (s *Stream) GoCtx(f TaskCtx) {
+	if err := s.submit(f); err != nil {
+		s.rejected(f, err)
+	}
+}
+
+// submit queues a task for execution unless the stream is closed or cancelled.
+// It holds the read lock so that the callback queue is not closed while the task is being queued.
+func (s *Stream) submit(f TaskCtx) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx := s.ctx
+	submitCtx, submitSpan := s.tracer.Start(ctx, "gostream.submit")
+	defer submitSpan.End()
+
+	switch {
+	case s.stopped.Load():
+		submitSpan.RecordError(gopool.ErrPoolClosed)
+
+		return gopool.ErrPoolClosed
+	case ctx.Err() != nil:
+		submitSpan.RecordError(gopool.ErrPoolCancelled)
+
+		return gopool.ErrPoolCancelled
 	}
 
 	queueCh := getCallbackChannel()
 	s.callbackQueueCh <- queueCh
+	s.stats.Submitted.Add(1)
+	id := s.taskIDs.Add(1)
 
 	// Submit the task for execution with panic protection.
 	s.workerPool.Go(func() error {
+		// Wait for the rate limit; once the stream is cancelled, the task runs right away.
+		s.rateLimiter.Wait(ctx)
+
+		spanCtx, span := s.tracer.Start(submitCtx, "gostream.execute")
+		defer span.End()
+
+		taskCtx := ctx
+		if spanCtx != submitCtx {
+			// Hand the execute span to the task.
+			taskCtx = tracing.Link(ctx, spanCtx)
+		}
+
+		start := s.stats.Start()
+		returned := false
 		defer func() {
 			// Recover from any potential panic in the task function and send a
 			// callbackData with the panic information to the callback reader. This
 			// ensures that the callback reader is not blocked waiting for a callback
 			// that will never come due to the panic.
 			if r := recover(); r != nil {
+				s.stats.Finish(start, returned, nil)
 				defer func() {
-					queueCh <- callbackData{fn: nil, err: nil}
+					queueCh <- callbackData{fn: nil, err: nil, ctx: submitCtx, id: id}
 				}()
-				s.panicHandler(r)
+				pe := newPanicError(r, "stream task", id)
+				span.RecordError(pe)
+				s.panicHandler(pe)
 			}
 		}()
 
 		// Execute the task function and send its result or error (if any) to the
 		// callback reader through the queue channel.
-		callbackFn, err := f()
-		queueCh <- callbackData{fn: callbackFn, err: err}
+		callbackFn, err := s.wrapTask(func() (Callback, error) { return f(taskCtx) })()
+		returned = true
+		s.stats.Finish(start, returned, nil)
+		if err != nil {
+			span.RecordError(err)
+		}
+		queueCh <- callbackData{fn: callbackFn, err: err, ctx: submitCtx, id: id}
 
 		return nil
 	})
+
+	return nil
+}
+
+// rejected passes a task that will never run to the OnRejected handler.
+func (s *Stream) rejected(f TaskCtx, err error) {
+	if s.rejectedHandler != nil {
+		s.rejectedHandler(f, err)
+	}
 }
 
 // Reset reactivates the stream, allowing new tasks to be submitted.
@@ -101,12 +191,28 @@ func (s *Stream) Reset() {
 // Wait blocks until all tasks and their callbacks have been executed.
 func (s *Stream) Wait() {
 	if s.stopped.CompareAndSwap(false, true) {
+		// Wait for submitters to leave before closing the callback queue.
+		s.mu.Lock()
 		close(s.callbackQueueCh)
+		s.mu.Unlock()
 		s.workerPool.Wait()
 		s.cancelFunc()
 	}
 }
 
+// Stats returns a snapshot of the stream's activity. Queued is the number of
+// tasks whose callbacks are waiting to be executed.
+func (s *Stream) Stats() gopool.Stats {
+	stats := s.stats.Snapshot()
+	poolStats := s.workerPool.Stats()
+	// The callback reader occupies one of the workers.
+	stats.Workers = max(poolStats.Workers-1, 0)
+	stats.Idle = poolStats.Idle
+	stats.Queued = int64(len(s.callbackQueueCh))
+
+	return stats
+}
+
 // Cancel cancels the stream, stopping all pending tasks.
 func (s *Stream) Cancel() {
 	s.cancelFunc()
@@ -126,7 +232,8 @@ func (s *Stream) callbackReader() {
 func (s *Stream) callbackHandler(data callbackData) {
 	defer func() {
 		if r := recover(); r != nil {
-			s.panicHandler(r)
+			s.stats.Panicked.Add(1)
+			s.panicHandler(newPanicError(r, "stream callback", data.id))
 		}
 	}()
 
@@ -134,13 +241,91 @@ func (s *Stream) callbackHandler(data callbackData) {
 		return
 	}
 	if data.err != nil {
+		s.stats.Failed.Add(1)
 		s.errorHandler(data.err)
 	}
 	if data.fn == nil {
 		return
 	}
 
-	if err := data.fn(); err != nil {
+	if err := s.runCallback(data); err != nil {
+		s.stats.Failed.Add(1)
 		s.errorHandler(err)
 	}
 }
+
+// runCallback runs the callback of a task within its callback span.
+func (s *Stream) runCallback(data callbackData) error {
+	_, span := s.tracer.Start(data.ctx, "gostream.callback")
+	defer span.End()
+	defer func() {
+		if r := recover(); r != nil {
+			// Record the panic on the span before it propagates to the panic handler.
+			pe := newPanicError(r, "stream callback", data.id)
+			span.RecordError(pe)
+			panic(pe)
+		}
+	}()
+
+	err := s.wrapCallback(data.fn)()
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+// wrapTask applies the task middleware to a task, the first middleware being the outermost one.
+func (s *Stream) wrapTask(f Task) Task {
+	for i := len(s.taskMiddleware) - 1; i >= 0; i-- {
+		f = s.taskMiddleware[i](f)
+	}
+
+	return f
+}
+
+// wrapCallback applies the callback middleware to a callback, the first middleware being the outermost one.
+func (s *Stream) wrapCallback(fn Callback) Callback {
+	for i := len(s.callbackMiddleware) - 1; i >= 0; i-- {
+		fn = s.callbackMiddleware[i](fn)
+	}
+
+	return fn
+}
+
+// newPanicError wraps a recovered panic value, recording the kind of function that panicked
+// and the ID of its task. It must be called in the deferred function that recovered the panic.
+func newPanicError(pc any, task string, id uint64) *syncgroup.PanicError {
+	pe := syncgroup.NewPanicError(pc)
+	if pe.Task == "" {
+		pe.Task = task
+	}
+	if pe.TaskID == 0 {
+		pe.TaskID = id
+	}
+
+	return pe
+}
+
+// log returns the logger of the stream, annotated with its name.
+func (s *Stream) log() *slog.Logger {
+	logger := s.logger
+	if logger == nil {
+		logger = syncgroup.DefaultLogger()
+	}
+	if s.name != "" {
+		logger = logger.With(slog.String("stream", s.name))
+	}
+
+	return logger
+}
+
+// logPanic is the default panic handler that logs the panic.
+func (s *Stream) logPanic(pc any) {
+	syncgroup.LogPanic(s.log(), pc)
+}
+
+// logError is the default error handler that logs the error.
+func (s *Stream) logError(err error) {
+	s.log().Error("task failed", slog.Any("error", err))
+}
@@ -3,12 +3,20 @@ package gostream_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/safeblock-dev/wr/gopool"
 	"github.com/safeblock-dev/wr/gostream"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/safeblock-dev/wr/tracing"
 	"github.com/stretchr/testify/require"
 )
 
@@ -97,6 +105,119 @@ func TestStream_Go(t *testing.T) {
 	})
 }
 
+func TestStream_OnRejected(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports tasks submitted after wait", func(t *testing.T) {
+		t.Parallel()
+
+		var reason error
+		stream := gostream.New(gostream.OnRejected(func(_ gostream.TaskCtx, err error) { reason = err }))
+		stream.Wait()
+
+		stream.Go(func() (gostream.Callback, error) { return nil, nil })
+		require.ErrorIs(t, reason, gopool.ErrPoolClosed)
+	})
+
+	t.Run("reports tasks submitted after cancel", func(t *testing.T) {
+		t.Parallel()
+
+		var reason error
+		stream := gostream.New(gostream.OnRejected(func(_ gostream.TaskCtx, err error) { reason = err }))
+		stream.Cancel()
+
+		stream.GoCtx(func(context.Context) (gostream.Callback, error) { return nil, nil })
+		stream.Wait()
+		require.ErrorIs(t, reason, gopool.ErrPoolCancelled)
+	})
+
+	t.Run("passes the task to the handler", func(t *testing.T) {
+		t.Parallel()
+
+		var requeued atomic.Bool
+		other := gostream.New()
+		stream := gostream.New(gostream.OnRejected(func(f gostream.TaskCtx, _ error) { other.GoCtx(f) }))
+		stream.Wait()
+
+		stream.Go(func() (gostream.Callback, error) {
+			return func() error { requeued.Store(true); return nil }, nil
+		})
+		other.Wait()
+		require.True(t, requeued.Load(), "Rejected task should be requeued")
+	})
+
+	t.Run("does not panic when submitting concurrently with wait", func(t *testing.T) {
+		t.Parallel()
+
+		var submitted, executed, rejected atomic.Int64
+		stream := gostream.New(
+			gostream.MaxGoroutines(2),
+			gostream.OnRejected(func(gostream.TaskCtx, error) { rejected.Add(1) }),
+		)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					submitted.Add(1)
+					stream.Go(func() (gostream.Callback, error) { executed.Add(1); return nil, nil })
+				}
+			}()
+		}
+		stream.Wait()
+		wg.Wait()
+
+		require.Equal(t, submitted.Load(), executed.Load()+rejected.Load(), "Every task should either run or be rejected")
+	})
+}
+
+func TestStream_GoCtx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cancel stops running task", func(t *testing.T) {
+		t.Parallel()
+
+		stream := gostream.New()
+		started := make(chan struct{})
+		var callbackCalled atomic.Bool
+
+		stream.GoCtx(func(ctx context.Context) (gostream.Callback, error) {
+			close(started)
+			<-ctx.Done()
+
+			return func() error {
+				callbackCalled.Store(true)
+				return nil
+			}, nil
+		})
+
+		<-started
+		stream.Cancel()
+		stream.Wait()
+
+		require.False(t, callbackCalled.Load(), "Callback should be skipped after cancel")
+	})
+
+	t.Run("callback runs with live context", func(t *testing.T) {
+		t.Parallel()
+
+		stream := gostream.New()
+		var ctxErr atomic.Value
+
+		stream.GoCtx(func(ctx context.Context) (gostream.Callback, error) {
+			return func() error {
+				ctxErr.Store(fmt.Sprint(ctx.Err()))
+				return nil
+			}, nil
+		})
+		stream.Wait()
+
+		require.Equal(t, "<nil>", ctxErr.Load())
+	})
+}
+
 func TestStream_ContextCancellation(t *testing.T) {
 	t.Parallel()
 
@@ -353,6 +474,133 @@ func TestStream_PanicHandler(t *testing.T) {
 	})
 }
 
+func TestStream_PanicError(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var recovered []*syncgroup.PanicError
+	stream := gostream.New(gostream.PanicHandler(func(pc any) {
+		mu.Lock()
+		defer mu.Unlock()
+		recovered = append(recovered, pc.(*syncgroup.PanicError)) //nolint: forcetypeassert
+	}))
+
+	stream.Go(func() (gostream.Callback, error) { panic("task panic") })
+	stream.Go(func() (gostream.Callback, error) {
+		return func() error { panic("callback panic") }, nil
+	})
+	stream.Wait()
+
+	require.Len(t, recovered, 2)
+	require.Equal(t, "panic in stream task: task panic", recovered[0].Error())
+	require.Equal(t, "panic in stream callback: callback panic", recovered[1].Error())
+	require.Contains(t, string(recovered[0].Stack), "gostream_test.TestStream_PanicError")
+}
+
+func TestStream_Middleware(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	stream := gostream.New(
+		gostream.MaxGoroutines(1),
+		gostream.Middleware(
+			func(next gostream.Task) gostream.Task {
+				return func() (gostream.Callback, error) { calls = append(calls, "outer task"); return next() }
+			},
+			func(next gostream.Task) gostream.Task {
+				return func() (gostream.Callback, error) { calls = append(calls, "inner task"); return next() }
+			},
+		),
+		gostream.CallbackMiddleware(func(next gostream.Callback) gostream.Callback {
+			return func() error { calls = append(calls, "callback middleware"); return next() }
+		}),
+	)
+
+	stream.Go(func() (gostream.Callback, error) {
+		calls = append(calls, "task")
+
+		return func() error { calls = append(calls, "callback"); return nil }, nil
+	})
+	stream.Wait()
+
+	require.Equal(t, []string{"outer task", "inner task", "task", "callback middleware", "callback"}, calls)
+}
+
+func TestStream_Tracer(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracing.NewRecorder()
+	expectedError := errors.New("callback error")
+	stream := gostream.New(gostream.Tracer(recorder), gostream.ErrorHandler(func(error) {}))
+
+	stream.GoCtx(func(ctx context.Context) (gostream.Callback, error) {
+		_, span := recorder.Start(ctx, "inner")
+		span.End()
+
+		return func() error { return expectedError }, nil
+	})
+	stream.Wait()
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 4)
+	require.Equal(t, "gostream.submit", spans[0].Name)
+	require.Equal(t, "gostream.execute", spans[1].Name)
+	require.Equal(t, spans[0].ID, spans[1].ParentID)
+	require.Equal(t, "inner", spans[2].Name)
+	require.Equal(t, spans[1].ID, spans[2].ParentID, "Task span should be a child of the execute span")
+	require.Equal(t, "gostream.callback", spans[3].Name)
+	require.Equal(t, spans[0].ID, spans[3].ParentID)
+	require.Equal(t, expectedError, spans[3].Err)
+	for _, span := range spans {
+		require.True(t, span.Ended)
+	}
+}
+
+func TestStream_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	var callbackCounter atomic.Int64
+	stream := gostream.New(gostream.MaxGoroutines(5), gostream.RateLimit(200, 1))
+	start := time.Now()
+
+	for i := 0; i < 10; i++ {
+		stream.Go(func() (gostream.Callback, error) {
+			return func() error { callbackCounter.Add(1); return nil }, nil
+		})
+	}
+	stream.Wait()
+
+	require.EqualValues(t, 10, callbackCounter.Load())
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond, "Tasks should be started at the given rate")
+}
+
+func TestStream_Stats(t *testing.T) {
+	t.Parallel()
+
+	stream := gostream.New(gostream.PanicHandler(func(any) {}), gostream.ErrorHandler(func(error) {}))
+	stream.Go(func() (gostream.Callback, error) {
+		return func() error { return nil }, nil
+	})
+	stream.Go(func() (gostream.Callback, error) {
+		return nil, errors.New("task error")
+	})
+	stream.Go(func() (gostream.Callback, error) {
+		return func() error { return errors.New("callback error") }, nil
+	})
+	stream.Go(func() (gostream.Callback, error) {
+		panic("test panic")
+	})
+	stream.Wait()
+
+	stats := stream.Stats()
+	require.EqualValues(t, 4, stats.Submitted)
+	require.EqualValues(t, 3, stats.Completed)
+	require.EqualValues(t, 2, stats.Failed)
+	require.EqualValues(t, 1, stats.Panicked)
+	require.Zero(t, stats.Running)
+	require.Zero(t, stats.Queued)
+}
+
 func TestStream_Wait(t *testing.T) {
 	t.Parallel()
 
@@ -407,3 +655,45 @@ func TestPool_Reset(t *testing.T) {
 		require.True(t, completed)
 	})
 }
+
+func TestStream_Logger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("logs panics with attributes", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+		stream := gostream.New(gostream.Logger(logger), gostream.Name("events"))
+
+		stream.Go(func() (gostream.Callback, error) { return nil, nil })
+		stream.Go(func() (gostream.Callback, error) {
+			return func() error { panic("test panic") }, nil
+		})
+		stream.Wait()
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &record))
+		require.Equal(t, "events", record["stream"])
+		require.Equal(t, "test panic", record["panic"])
+		require.Equal(t, "stream callback", record["task"])
+		require.EqualValues(t, 2, record["task_id"])
+		require.NotEmpty(t, record["stack"])
+	})
+
+	t.Run("logs errors without an error handler", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+		stream := gostream.New(gostream.Logger(logger))
+
+		stream.Go(func() (gostream.Callback, error) { return nil, errors.New("task error") })
+		stream.Wait()
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &record))
+		require.Equal(t, "task failed", record["msg"])
+		require.Equal(t, "task error", record["error"])
+	})
+}
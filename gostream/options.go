@@ -2,13 +2,17 @@ package gostream
 
 import (
 	"context"
-	"log"
+	"log/slog"
+
+	"github.com/safeblock-dev/wr/internal/ratelimit"
+	"github.com/safeblock-dev/wr/tracing"
 )
 
 // Option represents an option that can be passed when instantiating a Stream to customize it.
 type Option func(stream *Stream)
 
 // PanicHandler sets the panic handler function for the stream.
+// The handler receives a *syncgroup.PanicError carrying the stack of the panicking task or callback.
 func PanicHandler(panicHandler func(pc any)) Option {
 	return func(stream *Stream) {
 		stream.panicHandler = panicHandler
@@ -22,6 +26,15 @@ func ErrorHandler(errorHandler func(err error)) Option {
 	}
 }
 
+// OnRejected sets a handler for tasks that will never run because they were submitted
+// after the stream was closed or cancelled. The handler receives the task and the reason,
+// gopool.ErrPoolClosed or gopool.ErrPoolCancelled, so it can requeue the task or account for it.
+func OnRejected(handler func(f TaskCtx, err error)) Option {
+	return func(stream *Stream) {
+		stream.rejectedHandler = handler
+	}
+}
+
 // Context sets a parent context for the stream to stop all workers when it is cancelled.
 func Context(ctx context.Context) Option {
 	return func(stream *Stream) {
@@ -37,8 +50,50 @@ func MaxGoroutines(limit int) Option {
 	}
 }
 
-// defaultPanicHandler is the default panic handler that prints the panic information.
-func defaultPanicHandler(pc any) {
-	const red = "\u001B[31m"
-	log.Printf("[%[1]sERROR%[1]s] %v", red, pc)
+// RateLimit limits the rate at which tasks are started to rps tasks per second,
+// allowing bursts of up to burst tasks.
+func RateLimit(rps float64, burst int) Option {
+	return func(stream *Stream) {
+		stream.rateLimiter = ratelimit.New(rps, burst)
+	}
+}
+
+// Middleware adds middleware wrapping every task run by the stream.
+// The first middleware is the outermost one.
+func Middleware(middleware ...func(next Task) Task) Option {
+	return func(stream *Stream) {
+		stream.taskMiddleware = append(stream.taskMiddleware, middleware...)
+	}
+}
+
+// CallbackMiddleware adds middleware wrapping every callback run by the stream.
+// The first middleware is the outermost one.
+func CallbackMiddleware(middleware ...func(next Callback) Callback) Option {
+	return func(stream *Stream) {
+		stream.callbackMiddleware = append(stream.callbackMiddleware, middleware...)
+	}
+}
+
+// Tracer sets the tracer of the stream. A submit span is started when a task is submitted,
+// as a child of the stream's context, with an execute span for the task and a callback span
+// for its callback as children. By default tasks are not traced.
+func Tracer(tracer tracing.Tracer) Option {
+	return func(stream *Stream) {
+		stream.tracer = tracer
+	}
+}
+
+// Logger sets the logger of the stream. The default panic and error handlers log
+// panics and task errors with it. By default the logger set by syncgroup.SetDefaultLogger is used.
+func Logger(logger *slog.Logger) Option {
+	return func(stream *Stream) {
+		stream.logger = logger
+	}
+}
+
+// Name sets the name of the stream, added to its log records as the "stream" attribute.
+func Name(name string) Option {
+	return func(stream *Stream) {
+		stream.name = name
+	}
 }
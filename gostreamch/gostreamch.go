@@ -1,11 +1,13 @@
 package gostreamch
 
 import (
+	"slices"
 	"sync"
 	"sync/atomic"
 
-	"github.com/safeblock-dev/werr"
+	"github.com/safeblock-dev/wr/gopool"
 	"github.com/safeblock-dev/wr/gostream"
+	"github.com/safeblock-dev/wr/syncgroup"
 )
 
 // StreamCh is a wrapper around gostream.Stream with additional error handling.
@@ -14,6 +16,9 @@ type StreamCh struct {
 	err     error
 	errCh   chan error
 	errOnce sync.Once
+	mu      sync.Mutex
+	errs    []error
+	collect bool
 	stopped atomic.Bool
 }
 
@@ -35,11 +40,28 @@ func New(options ...gostream.Option) *StreamCh {
 	return &s
 }
 
-// Go submits a task to the stream for execution.
+// CollectErrors makes the stream gather every error and panic instead of cancelling
+// the stream on the first one, so that all tasks run. The first error is still sent
+// to the error channel; all of them are returned by Errors.
+// It must be called before any task is submitted.
+func (s *StreamCh) CollectErrors() *StreamCh {
+	s.collect = true
+
+	return s
+}
+
+// Go submits a task to the stream for execution. A task submitted after Wait(),
+// or after the first error unless errors are collected, is not run;
+// it is passed to the OnRejected handler instead.
 func (s *StreamCh) Go(f gostream.Task) {
-	if !s.stopped.Load() {
-		s.stream.Go(f)
-	}
+	s.stream.Go(f)
+}
+
+// GoCtx submits a task to the stream for execution, handing it the stream's context.
+// The context is cancelled on the first error or panic in the stream,
+// unless errors are collected.
+func (s *StreamCh) GoCtx(f gostream.TaskCtx) {
+	s.stream.GoCtx(f)
 }
 
 // Wait waits for all tasks in the stream to complete and closes the error channel.
@@ -58,6 +80,14 @@ func (s *StreamCh) Reset() {
 	s.err = nil
 	s.errCh = make(chan error, 1)
 	s.errOnce = sync.Once{}
+	s.mu.Lock()
+	s.errs = nil
+	s.mu.Unlock()
+}
+
+// Stats returns a snapshot of the stream's activity.
+func (s *StreamCh) Stats() gopool.Stats {
+	return s.stream.Stats()
 }
 
 // ErrorChannel returns a channel that can be used to receive errors that occur in the stream.
@@ -70,23 +100,48 @@ func (s *StreamCh) Error() error {
 	return s.err
 }
 
+// Errors returns all errors that occurred in the stream when errors are collected,
+// or the first error otherwise. The result can be combined with errors.Join.
+func (s *StreamCh) Errors() []error {
+	if !s.collect {
+		if s.err == nil {
+			return nil
+		}
+
+		return []error{s.err}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.errs)
+}
+
 // HasError returns true if an error has occurred in the stream.
 func (s *StreamCh) HasError() bool {
 	return s.err != nil
 }
 
 // panicHandler handles panics that occur in the stream by converting them
-// to errors and passing them to the error handler.
+// to *syncgroup.PanicError errors and passing them to the error handler.
 func (s *StreamCh) panicHandler(pc any) {
-	s.errorHandler(werr.PanicToError(pc))
+	s.errorHandler(syncgroup.NewPanicError(pc))
 }
 
 // errorHandler handles errors that occur in the stream by sending them
 // to the error channel and setting the error.
 func (s *StreamCh) errorHandler(err error) {
+	if s.collect {
+		s.mu.Lock()
+		s.errs = append(s.errs, err)
+		s.mu.Unlock()
+	}
+
 	s.errOnce.Do(func() {
 		s.err = err
 		s.errCh <- err
-		s.stream.Cancel()
+		if !s.collect {
+			s.stream.Cancel()
+		}
 	})
 }
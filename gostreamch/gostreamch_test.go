@@ -1,12 +1,15 @@
 package gostreamch_test
 
 import (
+	"context"
 	"errors"
 	"sync/atomic"
 	"testing"
 
+	"github.com/safeblock-dev/wr/gopool"
 	"github.com/safeblock-dev/wr/gostream"
 	"github.com/safeblock-dev/wr/gostreamch"
+	"github.com/safeblock-dev/wr/syncgroup"
 	"github.com/stretchr/testify/require"
 )
 
@@ -201,6 +204,30 @@ func TestStreamCh_Go(t *testing.T) {
 	})
 }
 
+func TestStreamCh_GoCtx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error cancels running tasks", func(t *testing.T) {
+		t.Parallel()
+
+		stream := gostreamch.New()
+		expectedError := errors.New("task error")
+
+		// Errors are handled in submission order, so the failing task goes first.
+		stream.GoCtx(func(_ context.Context) (gostream.Callback, error) {
+			return nil, expectedError
+		})
+		stream.GoCtx(func(ctx context.Context) (gostream.Callback, error) {
+			<-ctx.Done()
+			return nil, nil
+		})
+
+		stream.Wait()
+
+		require.Equal(t, expectedError, stream.Error())
+	})
+}
+
 func TestStreamCh_Reset(t *testing.T) {
 	t.Parallel()
 
@@ -240,4 +267,68 @@ func TestStreamCh_GoAfterWait(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("should report rejected tasks", func(t *testing.T) {
+		t.Parallel()
+
+		var reasons []error
+		ctx, cancel := context.WithCancel(context.Background())
+		stream := gostreamch.New(gostream.Context(ctx), gostream.OnRejected(func(_ gostream.TaskCtx, err error) {
+			reasons = append(reasons, err)
+		}))
+		cancel()
+
+		stream.Go(func() (gostream.Callback, error) { return nil, nil })
+		stream.Wait()
+		stream.Go(func() (gostream.Callback, error) { return nil, nil })
+
+		require.Equal(t, []error{gopool.ErrPoolCancelled, gopool.ErrPoolClosed}, reasons)
+	})
+}
+
+func TestStreamCh_CollectErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("collects all errors without cancelling", func(t *testing.T) {
+		t.Parallel()
+
+		stream := gostreamch.New(gostream.MaxGoroutines(2)).CollectErrors()
+		errTask, errCallback := errors.New("task error"), errors.New("callback error")
+
+		var completed atomic.Int64
+		stream.Go(func() (gostream.Callback, error) { return nil, errTask })
+		stream.Go(func() (gostream.Callback, error) {
+			return func() error { return errCallback }, nil
+		})
+		stream.Go(func() (gostream.Callback, error) {
+			return func() error { panic("test panic") }, nil
+		})
+		for i := 0; i < 5; i++ {
+			stream.Go(func() (gostream.Callback, error) {
+				return func() error { completed.Add(1); return nil }, nil
+			})
+		}
+		stream.Wait()
+
+		require.Equal(t, errTask, <-stream.ErrorChannel(), "The first error should be sent to the channel")
+		errs := stream.Errors()
+		require.Len(t, errs, 3)
+		require.Equal(t, []error{errTask, errCallback}, errs[:2], "Errors are handled in submission order")
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, errs[2], &pe)
+		require.EqualValues(t, 5, completed.Load(), "Other callbacks should run")
+	})
+
+	t.Run("resets the collected errors", func(t *testing.T) {
+		t.Parallel()
+
+		stream := gostreamch.New().CollectErrors()
+
+		stream.Go(func() (gostream.Callback, error) { return nil, errors.New("task error") })
+		stream.Wait()
+		require.Len(t, stream.Errors(), 1)
+
+		stream.Reset()
+		require.Empty(t, stream.Errors())
+	})
 }
@@ -0,0 +1,190 @@
+package leak
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// modulePrefix is the import path prefix of the packages of this module.
+const modulePrefix = "github.com/safeblock-dev/wr/"
+
+// Task is a task that was still running when a report was made.
+type Task struct {
+	CallSite string    // CallSite is the file and line where the task was submitted.
+	Started  time.Time // Started is the time the task started running.
+	Stack    []byte    // Stack is the current stack of the goroutine running the task.
+}
+
+// Report lists the tasks still running after a wait outlived the detector's timeout.
+type Report struct {
+	Waited time.Duration // Waited is the time the wait had been blocked for.
+	Tasks  []Task        // Tasks are the running tasks, the oldest first.
+}
+
+// Detector tracks running tasks and reports them when a wait takes too long.
+// A nil Detector tracks nothing.
+type Detector struct {
+	mu      sync.Mutex          // mu protects running.
+	timeout time.Duration       // timeout is the time a wait may take before running tasks are reported.
+	report  func(Report)        // report receives the running tasks.
+	running map[*entry]struct{} // running stores the running tasks.
+}
+
+// entry is a running task.
+type entry struct {
+	callSite string    // callSite is the file and line where the task was submitted.
+	started  time.Time // started is the time the task started running.
+	goid     string    // goid is the ID of the goroutine running the task.
+}
+
+// New creates a Detector reporting the running tasks to report once a wait takes longer than timeout.
+// It returns nil if timeout is not positive or report is nil.
+func New(timeout time.Duration, report func(Report)) *Detector {
+	if timeout <= 0 || report == nil {
+		return nil
+	}
+
+	return &Detector{ //nolint: exhaustruct
+		timeout: timeout,
+		report:  report,
+		running: make(map[*entry]struct{}),
+	}
+}
+
+// CallSite returns the file and line of the first caller outside the packages of this module,
+// where a task is being submitted.
+func (d *Detector) CallSite() string {
+	if d == nil {
+		return ""
+	}
+
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !internal(frame.Function) {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// Start records that the calling goroutine runs a task submitted at callSite.
+// The returned function must be called once the task has finished.
+func (d *Detector) Start(callSite string) func() {
+	if d == nil {
+		return func() {}
+	}
+
+	e := &entry{callSite: callSite, started: time.Now(), goid: goid()}
+
+	d.mu.Lock()
+	d.running[e] = struct{}{}
+	d.mu.Unlock()
+
+	return func() {
+		d.mu.Lock()
+		delete(d.running, e)
+		d.mu.Unlock()
+	}
+}
+
+// Watch starts watching a wait. If the returned function is not called within the timeout,
+// the running tasks are reported from another goroutine, without interrupting the wait.
+func (d *Detector) Watch() func() {
+	if d == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	timer := time.AfterFunc(d.timeout, func() {
+		d.report(d.snapshot(time.Since(start)))
+	})
+
+	return func() { timer.Stop() }
+}
+
+// snapshot returns the report of the running tasks.
+func (d *Detector) snapshot(waited time.Duration) Report {
+	d.mu.Lock()
+	entries := make([]*entry, 0, len(d.running))
+	for e := range d.running {
+		entries = append(entries, e)
+	}
+	d.mu.Unlock()
+
+	slices.SortFunc(entries, func(a, b *entry) int {
+		return a.started.Compare(b.started)
+	})
+
+	stacks := goroutineStacks()
+	tasks := make([]Task, len(entries))
+	for i, e := range entries {
+		tasks[i] = Task{CallSite: e.callSite, Started: e.started, Stack: stacks[e.goid]}
+	}
+
+	return Report{Waited: waited, Tasks: tasks}
+}
+
+// internal reports whether function belongs to a library package of this module.
+func internal(function string) bool {
+	rest, ok := strings.CutPrefix(function, modulePrefix)
+	if !ok {
+		return false
+	}
+
+	pkg, _, _ := strings.Cut(rest, ".")
+
+	return !strings.HasSuffix(pkg, "_test")
+}
+
+// goid returns the ID of the calling goroutine, parsed from the header of its stack.
+func goid() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	id, _ := parseHeader(buf)
+
+	return id
+}
+
+// goroutineStacks returns the stacks of all goroutines by goroutine ID.
+func goroutineStacks() map[string][]byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[string][]byte)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if id, ok := parseHeader(stack); ok {
+			stacks[id] = stack
+		}
+	}
+
+	return stacks
+}
+
+// parseHeader returns the goroutine ID from a stack starting with "goroutine <id> [<state>]:".
+func parseHeader(stack []byte) (string, bool) {
+	rest, ok := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !ok {
+		return "", false
+	}
+
+	id, _, ok := bytes.Cut(rest, []byte(" "))
+
+	return string(id), ok
+}
@@ -0,0 +1,68 @@
+package leak_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/internal/leak"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports running tasks", func(t *testing.T) {
+		t.Parallel()
+
+		reports := make(chan leak.Report, 1)
+		detector := leak.New(10*time.Millisecond, func(r leak.Report) { reports <- r })
+
+		callSite := detector.CallSite()
+		started, release := make(chan struct{}), make(chan struct{})
+		go func() {
+			defer detector.Start(callSite)()
+			close(started)
+			<-release
+		}()
+		<-started
+
+		finished := detector.Start("finished")
+		finished()
+
+		stop := detector.Watch()
+		report := <-reports
+		stop()
+		close(release)
+
+		require.GreaterOrEqual(t, report.Waited, 10*time.Millisecond)
+		require.Len(t, report.Tasks, 1, "Finished tasks should not be reported")
+		require.Contains(t, report.Tasks[0].CallSite, "leak_test.go:")
+		require.False(t, report.Tasks[0].Started.IsZero())
+		require.Contains(t, string(report.Tasks[0].Stack), "leak_test.TestDetector")
+	})
+
+	t.Run("does not report a wait that finishes in time", func(t *testing.T) {
+		t.Parallel()
+
+		var reported bool
+		detector := leak.New(time.Hour, func(leak.Report) { reported = true })
+
+		stop := detector.Watch()
+		stop()
+
+		require.False(t, reported)
+	})
+
+	t.Run("is disabled without a timeout", func(t *testing.T) {
+		t.Parallel()
+
+		detector := leak.New(0, func(leak.Report) {})
+
+		require.Nil(t, detector)
+		require.Empty(t, detector.CallSite())
+		require.NotPanics(t, func() {
+			detector.Start("")()
+			detector.Watch()()
+		})
+	})
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket limiting the rate of events.
+// A nil Limiter allows all events.
+type Limiter struct {
+	mu       sync.Mutex    // mu protects tokens and last.
+	interval time.Duration // interval is the time needed to refill one token.
+	burst    float64       // burst is the capacity of the bucket.
+	tokens   float64       // tokens is the number of available tokens, negative when reserved in advance.
+	last     time.Time     // last is the time tokens were last refilled.
+}
+
+// New creates a Limiter allowing rps events per second with bursts of up to burst events.
+// It returns nil if rps is not positive.
+func New(rps float64, burst int) *Limiter {
+	if rps <= 0 {
+		return nil
+	}
+
+	return &Limiter{ //nolint: exhaustruct
+		interval: time.Duration(float64(time.Second) / rps),
+		burst:    float64(max(burst, 1)),
+		tokens:   float64(max(burst, 1)),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until an event is allowed or ctx is done.
+// It reports false if ctx is done before the event is allowed.
+func (l *Limiter) Wait(ctx context.Context) bool {
+	if l == nil {
+		return true
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// reserve takes a token and returns how long to wait until it is available.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+	l.last = now
+	l.tokens--
+
+	if l.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-l.tokens * float64(l.interval))
+}
@@ -0,0 +1,47 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/internal/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	t.Run("allows burst then limits rate", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := ratelimit.New(100, 5)
+		start := time.Now()
+
+		for i := 0; i < 10; i++ {
+			require.True(t, limiter.Wait(context.Background()))
+		}
+
+		// The burst is free, the remaining 5 events take 10ms each.
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("stops waiting on cancel", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := ratelimit.New(0.1, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.True(t, limiter.Wait(ctx), "The burst should be available")
+		require.False(t, limiter.Wait(ctx))
+	})
+
+	t.Run("nil limiter allows all events", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := ratelimit.New(0, 1)
+		require.Nil(t, limiter)
+		require.True(t, limiter.Wait(context.Background()))
+	})
+}
@@ -0,0 +1,86 @@
+// Package taskstats collects the task statistics reported by the pools of this module.
+package taskstats
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of the activity of a pool.
+type Stats struct {
+	Workers     int64         // Workers is the number of running workers.
+	Running     int64         // Running is the number of tasks being executed.
+	Idle        int64         // Idle is the number of workers waiting for a task.
+	Queued      int64         // Queued is the number of tasks waiting for a worker.
+	Submitted   uint64        // Submitted is the number of tasks accepted for execution.
+	Completed   uint64        // Completed is the number of tasks that returned, including failed ones.
+	Failed      uint64        // Failed is the number of tasks that returned an error.
+	Panicked    uint64        // Panicked is the number of tasks that panicked.
+	Rejected    uint64        // Rejected is the number of tasks discarded, rejected or cancelled before they started.
+	AvgDuration time.Duration // AvgDuration is the average execution time of finished tasks.
+	MaxDuration time.Duration // MaxDuration is the longest execution time of a finished task.
+}
+
+// Counters collects the statistics of a pool. The worker and queue figures
+// of a snapshot are left to the pool.
+type Counters struct {
+	Running       atomic.Int64  // Running is the number of tasks being executed.
+	Submitted     atomic.Uint64 // Submitted is the number of tasks accepted for execution.
+	Completed     atomic.Uint64 // Completed is the number of tasks that returned.
+	Failed        atomic.Uint64 // Failed is the number of tasks that returned an error.
+	Panicked      atomic.Uint64 // Panicked is the number of tasks that panicked.
+	Rejected      atomic.Uint64 // Rejected is the number of rejected tasks.
+	totalDuration atomic.Int64  // totalDuration is the sum of the execution times of finished tasks.
+	maxDuration   atomic.Int64  // maxDuration is the longest execution time of a finished task.
+}
+
+// Start marks the beginning of a task execution and returns its start time.
+func (c *Counters) Start() time.Time {
+	c.Running.Add(1)
+
+	return time.Now()
+}
+
+// Finish marks the end of a task execution started at start.
+// A task that returned neither normally nor with an error has panicked.
+func (c *Counters) Finish(start time.Time, returned bool, err error) {
+	c.Running.Add(-1)
+
+	switch {
+	case !returned:
+		c.Panicked.Add(1)
+	case err != nil:
+		c.Failed.Add(1)
+		c.Completed.Add(1)
+	default:
+		c.Completed.Add(1)
+	}
+
+	duration := int64(time.Since(start))
+	c.totalDuration.Add(duration)
+	for {
+		longest := c.maxDuration.Load()
+		if duration <= longest || c.maxDuration.CompareAndSwap(longest, duration) {
+			break
+		}
+	}
+}
+
+// Snapshot returns the collected statistics.
+func (c *Counters) Snapshot() Stats {
+	stats := Stats{ //nolint: exhaustruct
+		Running:     c.Running.Load(),
+		Submitted:   c.Submitted.Load(),
+		Completed:   c.Completed.Load(),
+		Failed:      c.Failed.Load(),
+		Panicked:    c.Panicked.Load(),
+		Rejected:    c.Rejected.Load(),
+		MaxDuration: time.Duration(c.maxDuration.Load()),
+	}
+
+	if finished := stats.Completed + stats.Panicked; finished > 0 {
+		stats.AvgDuration = time.Duration(uint64(c.totalDuration.Load()) / finished) //nolint: gosec
+	}
+
+	return stats
+}
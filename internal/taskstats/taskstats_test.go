@@ -0,0 +1,50 @@
+package taskstats_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/internal/taskstats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCounters_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("counts finished tasks", func(t *testing.T) {
+		t.Parallel()
+
+		var c taskstats.Counters
+		c.Submitted.Add(3)
+		c.Finish(c.Start(), true, nil)
+		c.Finish(c.Start(), true, errors.New("task error"))
+		c.Finish(c.Start(), false, nil)
+		running := c.Start()
+
+		stats := c.Snapshot()
+		require.EqualValues(t, 3, stats.Submitted)
+		require.EqualValues(t, 2, stats.Completed)
+		require.EqualValues(t, 1, stats.Failed)
+		require.EqualValues(t, 1, stats.Panicked)
+		require.EqualValues(t, 1, stats.Running)
+
+		c.Finish(running, true, nil)
+		require.Zero(t, c.Snapshot().Running)
+	})
+
+	t.Run("tracks average and longest durations", func(t *testing.T) {
+		t.Parallel()
+
+		var c taskstats.Counters
+		for _, d := range []time.Duration{10 * time.Millisecond, 30 * time.Millisecond} {
+			c.Start()
+			c.Finish(time.Now().Add(-d), true, nil) // The task ran for d.
+		}
+
+		stats := c.Snapshot()
+		require.GreaterOrEqual(t, stats.MaxDuration, 30*time.Millisecond)
+		require.GreaterOrEqual(t, stats.AvgDuration, 20*time.Millisecond)
+		require.Less(t, stats.AvgDuration, stats.MaxDuration)
+	})
+}
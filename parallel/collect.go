@@ -0,0 +1,267 @@
+package parallel
+
+import (
+	"context"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// results collects the outputs of calls by the index of their element.
+type results[R any] struct {
+	mu     sync.Mutex // mu protects values.
+	values []R        // values stores the outputs by index.
+}
+
+// set stores the output of the call for the element at index.
+func (r *results[R]) set(index int, value R) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if index >= len(r.values) {
+		r.values = slices.Grow(r.values, index+1-len(r.values))[:index+1]
+	}
+	r.values[index] = value
+}
+
+// all returns the outputs of n elements, the missing ones being zero values.
+func (r *results[R]) all(n int) []R {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n > len(r.values) {
+		r.values = slices.Grow(r.values, n-len(r.values))[:n]
+	}
+
+	return r.values[:n]
+}
+
+// Map calls f for every element of items concurrently and returns the outputs in input order.
+// The outputs of skipped elements are zero values.
+func Map[T, R any](
+	ctx context.Context, items []T, f func(ctx context.Context, item T) R, options ...Option,
+) ([]R, error) {
+	return MapSeq(ctx, slices.Values(items), f, options...)
+}
+
+// MapSeq is like Map for the elements of seq.
+func MapSeq[T, R any](
+	ctx context.Context, seq iter.Seq[T], f func(ctx context.Context, item T) R, options ...Option,
+) ([]R, error) {
+	return MapErrSeq(ctx, seq, func(ctx context.Context, item T) (R, error) {
+		return f(ctx, item), nil
+	}, options...)
+}
+
+// MapMap calls f for every key and value of m concurrently and returns the outputs by key.
+// Skipped keys are missing from the output.
+func MapMap[K comparable, V, R any](
+	ctx context.Context, m map[K]V, f func(ctx context.Context, key K, value V) R, options ...Option,
+) (map[K]R, error) {
+	return MapErrMap(ctx, m, func(ctx context.Context, key K, value V) (R, error) {
+		return f(ctx, key, value), nil
+	}, options...)
+}
+
+// MapSeq2 is like Map for the pairs of seq.
+func MapSeq2[K, V, R any](
+	ctx context.Context, seq iter.Seq2[K, V], f func(ctx context.Context, key K, value V) R, options ...Option,
+) ([]R, error) {
+	return MapErrSeq2(ctx, seq, func(ctx context.Context, key K, value V) (R, error) {
+		return f(ctx, key, value), nil
+	}, options...)
+}
+
+// MapErr calls f for every element of items concurrently and returns the outputs in input order.
+// The outputs of failed or skipped elements are zero values.
+func MapErr[T, R any](
+	ctx context.Context, items []T, f func(ctx context.Context, item T) (R, error), options ...Option,
+) ([]R, error) {
+	return MapErrSeq(ctx, slices.Values(items), f, options...)
+}
+
+// MapErrSeq is like MapErr for the elements of seq.
+func MapErrSeq[T, R any](
+	ctx context.Context, seq iter.Seq[T], f func(ctx context.Context, item T) (R, error), options ...Option,
+) ([]R, error) {
+	var out results[R]
+	n, err := run(ctx, seq, func(ctx context.Context, index int, item T) error {
+		value, err := f(ctx, item)
+		if err != nil {
+			return err
+		}
+		out.set(index, value)
+
+		return nil
+	}, newConfig(options))
+
+	return out.all(n), err
+}
+
+// MapErrMap calls f for every key and value of m concurrently and returns the outputs by key.
+// Failed or skipped keys are missing from the output.
+func MapErrMap[K comparable, V, R any](
+	ctx context.Context, m map[K]V, f func(ctx context.Context, key K, value V) (R, error), options ...Option,
+) (map[K]R, error) {
+	var mu sync.Mutex
+	out := make(map[K]R, len(m))
+	err := ForEachMap(ctx, m, func(ctx context.Context, key K, value V) error {
+		output, err := f(ctx, key, value)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		out[key] = output
+		mu.Unlock()
+
+		return nil
+	}, options...)
+
+	return out, err
+}
+
+// MapErrSeq2 is like MapErr for the pairs of seq.
+func MapErrSeq2[K, V, R any](
+	ctx context.Context, seq iter.Seq2[K, V], f func(ctx context.Context, key K, value V) (R, error), options ...Option,
+) ([]R, error) {
+	return MapErrSeq(ctx, pairs(seq), func(ctx context.Context, p pair[K, V]) (R, error) {
+		return f(ctx, p.key, p.value)
+	}, options...)
+}
+
+// Filter calls keep for every element of items concurrently and returns the elements
+// it reported true for, in input order. Failed or skipped elements are not returned.
+func Filter[T any](
+	ctx context.Context, items []T, keep func(ctx context.Context, item T) (bool, error), options ...Option,
+) ([]T, error) {
+	return FilterSeq(ctx, slices.Values(items), keep, options...)
+}
+
+// FilterSeq is like Filter for the elements of seq.
+func FilterSeq[T any](
+	ctx context.Context, seq iter.Seq[T], keep func(ctx context.Context, item T) (bool, error), options ...Option,
+) ([]T, error) {
+	type kept struct {
+		value T
+		ok    bool
+	}
+
+	var out results[kept]
+	n, err := run(ctx, seq, func(ctx context.Context, index int, item T) error {
+		ok, err := keep(ctx, item)
+		if err != nil {
+			return err
+		}
+		out.set(index, kept{value: item, ok: ok})
+
+		return nil
+	}, newConfig(options))
+
+	var filtered []T
+	for _, k := range out.all(n) {
+		if k.ok {
+			filtered = append(filtered, k.value)
+		}
+	}
+
+	return filtered, err
+}
+
+// FilterMap calls keep for every key and value of m concurrently and returns the entries
+// it reported true for. Failed or skipped entries are not returned.
+func FilterMap[K comparable, V any](
+	ctx context.Context, m map[K]V, keep func(ctx context.Context, key K, value V) (bool, error), options ...Option,
+) (map[K]V, error) {
+	kept, err := FilterSeq2(ctx, maps.All(m), keep, options...)
+
+	return maps.Collect(kept), err
+}
+
+// FilterSeq2 is like Filter for the pairs of seq. The kept pairs are returned as a sequence,
+// which may be iterated over several times.
+func FilterSeq2[K, V any](
+	ctx context.Context,
+	seq iter.Seq2[K, V],
+	keep func(ctx context.Context, key K, value V) (bool, error),
+	options ...Option,
+) (iter.Seq2[K, V], error) {
+	filtered, err := FilterSeq(ctx, pairs(seq), func(ctx context.Context, p pair[K, V]) (bool, error) {
+		return keep(ctx, p.key, p.value)
+	}, options...)
+
+	return func(yield func(K, V) bool) {
+		for _, p := range filtered {
+			if !yield(p.key, p.value) {
+				return
+			}
+		}
+	}, err
+}
+
+// Reduce calls f for every element of items concurrently, then folds the outputs
+// in input order with combine, starting from initial. If any call fails or ctx is done,
+// the zero value is returned with the error.
+func Reduce[T, R any](
+	ctx context.Context,
+	items []T,
+	initial R,
+	f func(ctx context.Context, item T) (R, error),
+	combine func(acc, value R) R,
+	options ...Option,
+) (R, error) {
+	return ReduceSeq(ctx, slices.Values(items), initial, f, combine, options...)
+}
+
+// ReduceSeq is like Reduce for the elements of seq.
+func ReduceSeq[T, R any](
+	ctx context.Context,
+	seq iter.Seq[T],
+	initial R,
+	f func(ctx context.Context, item T) (R, error),
+	combine func(acc, value R) R,
+	options ...Option,
+) (R, error) {
+	values, err := MapErrSeq(ctx, seq, f, options...)
+	if err != nil {
+		var zero R
+
+		return zero, err
+	}
+
+	acc := initial
+	for _, value := range values {
+		acc = combine(acc, value)
+	}
+
+	return acc, nil
+}
+
+// ReduceMap calls f for every key and value of m concurrently, then folds the outputs
+// with combine, starting from initial. The outputs are folded in the iteration order of m,
+// which is unspecified. If any call fails or ctx is done, the zero value is returned with the error.
+func ReduceMap[K comparable, V, R any](
+	ctx context.Context,
+	m map[K]V,
+	initial R,
+	f func(ctx context.Context, key K, value V) (R, error),
+	combine func(acc, value R) R,
+	options ...Option,
+) (R, error) {
+	return ReduceSeq2(ctx, maps.All(m), initial, f, combine, options...)
+}
+
+// ReduceSeq2 is like Reduce for the pairs of seq.
+func ReduceSeq2[K, V, R any](
+	ctx context.Context,
+	seq iter.Seq2[K, V],
+	initial R,
+	f func(ctx context.Context, key K, value V) (R, error),
+	combine func(acc, value R) R,
+	options ...Option,
+) (R, error) {
+	return ReduceSeq(ctx, pairs(seq), initial, func(ctx context.Context, p pair[K, V]) (R, error) {
+		return f(ctx, p.key, p.value)
+	}, combine, options...)
+}
@@ -0,0 +1,44 @@
+package parallel
+
+import (
+	"runtime"
+)
+
+// Option represents an option that can be passed to the functions of this package to customize them.
+type Option func(cfg *config)
+
+// config holds the settings of a parallel run.
+type config struct {
+	limit   int  // limit is the maximum number of concurrently running calls.
+	collect bool // collect indicates if all errors are collected instead of stopping at the first one.
+}
+
+// newConfig returns the settings of a parallel run with the provided options applied.
+func newConfig(options []Option) config {
+	cfg := config{
+		limit:   runtime.GOMAXPROCS(0),
+		collect: false,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// Limit sets the maximum number of concurrently running calls. A limit below 1 removes the limit.
+// By default it is runtime.GOMAXPROCS(0).
+func Limit(n int) Option {
+	return func(cfg *config) {
+		cfg.limit = n
+	}
+}
+
+// CollectErrors makes a run process every element and return the errors of all failed
+// calls joined in input order, instead of stopping at the first error.
+func CollectErrors() Option {
+	return func(cfg *config) {
+		cfg.collect = true
+	}
+}
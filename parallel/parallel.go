@@ -0,0 +1,165 @@
+// Package parallel runs a function over the elements of slices, maps and iterators
+// concurrently on a gopool.Pool, with a concurrency limit and context cancellation.
+//
+// By default the first error cancels the context of running calls, stops the iteration
+// and is returned. With CollectErrors every element is processed and all errors are returned
+// joined in input order. A panic in a call is returned as a *syncgroup.PanicError.
+// If ctx is done before every element was processed, its error is returned as well.
+// Outputs preserve the input order.
+package parallel
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/safeblock-dev/wr/gopool"
+	"github.com/safeblock-dev/wr/syncgroup"
+)
+
+// ForEach calls f for every element of items concurrently.
+func ForEach[T any](
+	ctx context.Context, items []T, f func(ctx context.Context, item T) error, options ...Option,
+) error {
+	return ForEachSeq(ctx, slices.Values(items), f, options...)
+}
+
+// ForEachMap calls f for every key and value of m concurrently.
+func ForEachMap[K comparable, V any](
+	ctx context.Context, m map[K]V, f func(ctx context.Context, key K, value V) error, options ...Option,
+) error {
+	return ForEachSeq2(ctx, maps.All(m), f, options...)
+}
+
+// ForEachSeq calls f for every element of seq concurrently. The sequence is consumed
+// as calls start, so it may be unbounded as long as it stops once ctx is done.
+func ForEachSeq[T any](
+	ctx context.Context, seq iter.Seq[T], f func(ctx context.Context, item T) error, options ...Option,
+) error {
+	_, err := run(ctx, seq, func(ctx context.Context, _ int, item T) error {
+		return f(ctx, item)
+	}, newConfig(options))
+
+	return err
+}
+
+// ForEachSeq2 calls f for every pair of seq concurrently.
+func ForEachSeq2[K, V any](
+	ctx context.Context, seq iter.Seq2[K, V], f func(ctx context.Context, key K, value V) error, options ...Option,
+) error {
+	_, err := run(ctx, pairs(seq), func(ctx context.Context, _ int, p pair[K, V]) error {
+		return f(ctx, p.key, p.value)
+	}, newConfig(options))
+
+	return err
+}
+
+// pair is an element of an iter.Seq2.
+type pair[K, V any] struct {
+	key   K
+	value V
+}
+
+// pairs returns the elements of seq as pairs.
+func pairs[K, V any](seq iter.Seq2[K, V]) iter.Seq[pair[K, V]] {
+	return func(yield func(pair[K, V]) bool) {
+		for k, v := range seq {
+			if !yield(pair[K, V]{key: k, value: v}) {
+				return
+			}
+		}
+	}
+}
+
+// indexedError is the error of the call for the element at index.
+type indexedError struct {
+	index int
+	err   error
+}
+
+// run calls f for every element of seq on a pool, passing the index of the element.
+// It returns the number of elements consumed from seq.
+func run[T any](
+	ctx context.Context, seq iter.Seq[T], f func(ctx context.Context, index int, item T) error, cfg config,
+) (int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		errs    []indexedError
+		skipped atomic.Bool
+	)
+	pool := gopool.New(
+		gopool.Context(ctx),
+		gopool.MaxGoroutines(cfg.limit),
+		gopool.OnRejected(func(func(context.Context) error, error) {
+			skipped.Store(true) // The pool was cancelled while the element waited for a worker.
+		}),
+	)
+
+	index := 0
+	for item := range seq {
+		if ctx.Err() != nil {
+			skipped.Store(true)
+
+			break
+		}
+
+		i := index
+		index++
+		pool.GoCtx(func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				skipped.Store(true) // Cancelled before the call started.
+
+				return nil
+			}
+
+			if err := call(ctx, i, item, f); err != nil {
+				mu.Lock()
+				errs = append(errs, indexedError{index: i, err: err})
+				mu.Unlock()
+
+				if !cfg.collect {
+					cancel()
+				}
+			}
+
+			return nil
+		})
+	}
+	pool.Wait()
+
+	if !cfg.collect && len(errs) > 0 {
+		return index, errs[0].err // The first error to occur.
+	}
+
+	slices.SortFunc(errs, func(a, b indexedError) int { return a.index - b.index })
+	joined := make([]error, 0, len(errs)+1)
+	for _, e := range errs {
+		joined = append(joined, e.err)
+	}
+	if skipped.Load() {
+		// Elements were skipped because the caller's context is done.
+		joined = append(joined, ctx.Err())
+	}
+
+	return index, errors.Join(joined...)
+}
+
+// call calls f, returning a panic as a *syncgroup.PanicError.
+func call[T any](
+	ctx context.Context, index int, item T, f func(ctx context.Context, index int, item T) error,
+) (err error) {
+	defer func() {
+		if pc := recover(); pc != nil {
+			err = syncgroup.NewPanicError(pc)
+		}
+	}()
+
+	return f(ctx, index, item)
+}
@@ -0,0 +1,317 @@
+package parallel_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/parallel"
+	"github.com/safeblock-dev/wr/syncgroup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach(t *testing.T) {
+	t.Parallel()
+
+	t.Run("calls f for every element within the limit", func(t *testing.T) {
+		t.Parallel()
+
+		var sum, running, peak atomic.Int64
+		items := []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+		err := parallel.ForEach(context.Background(), items, func(_ context.Context, item int64) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+			sum.Add(item)
+
+			return nil
+		}, parallel.Limit(3))
+
+		require.NoError(t, err)
+		require.EqualValues(t, 55, sum.Load())
+		require.LessOrEqual(t, peak.Load(), int64(3), "The limit should be respected")
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		t.Parallel()
+
+		expectedError := errors.New("task error")
+		var calls atomic.Int64
+		items := make([]int, 100)
+
+		err := parallel.ForEach(context.Background(), items, func(context.Context, int) error {
+			calls.Add(1)
+
+			return expectedError
+		}, parallel.Limit(2))
+
+		require.Equal(t, expectedError, err)
+		require.Less(t, calls.Load(), int64(len(items)), "The iteration should stop")
+	})
+
+	t.Run("collects all errors in input order", func(t *testing.T) {
+		t.Parallel()
+
+		var calls atomic.Int64
+		items := []int{0, 1, 2, 3, 4, 5}
+
+		err := parallel.ForEach(context.Background(), items, func(_ context.Context, item int) error {
+			calls.Add(1)
+			if item%2 == 1 {
+				time.Sleep(time.Duration(10-item) * time.Millisecond) // Later elements fail first.
+
+				return errors.New(strconv.Itoa(item))
+			}
+
+			return nil
+		}, parallel.CollectErrors())
+
+		require.EqualValues(t, len(items), calls.Load(), "Every element should be processed")
+		require.EqualError(t, err, "1\n3\n5")
+	})
+
+	t.Run("returns a panic as an error", func(t *testing.T) {
+		t.Parallel()
+
+		err := parallel.ForEach(context.Background(), []int{1}, func(context.Context, int) error {
+			panic("test panic")
+		})
+
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, err, &pe)
+		require.Equal(t, "test panic", pe.Value)
+	})
+
+	t.Run("stops an unbounded sequence when the context is done", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		naturals := func(yield func(int) bool) {
+			for i := 0; yield(i); i++ {
+			}
+		}
+
+		err := parallel.ForEachSeq(ctx, naturals, func(_ context.Context, item int) error {
+			if item == 10 {
+				cancel()
+			}
+
+			return nil
+		}, parallel.Limit(2))
+
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("iterates over maps and pairs", func(t *testing.T) {
+		t.Parallel()
+
+		m := map[string]int{"a": 1, "b": 2, "c": 3}
+		var mu sync.Mutex
+		got := make(map[string]int)
+		visit := func(_ context.Context, key string, value int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got[key] += value
+
+			return nil
+		}
+
+		require.NoError(t, parallel.ForEachMap(context.Background(), m, visit))
+		require.NoError(t, parallel.ForEachSeq2(context.Background(), maps.All(m), visit))
+		require.Equal(t, map[string]int{"a": 2, "b": 4, "c": 6}, got)
+	})
+}
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+
+	t.Run("preserves input order", func(t *testing.T) {
+		t.Parallel()
+
+		items := []int{5, 4, 3, 2, 1}
+
+		results, err := parallel.Map(context.Background(), items, func(_ context.Context, item int) string {
+			time.Sleep(time.Duration(item) * time.Millisecond) // Later elements finish first.
+
+			return strconv.Itoa(item)
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []string{"5", "4", "3", "2", "1"}, results)
+	})
+
+	t.Run("maps a sequence", func(t *testing.T) {
+		t.Parallel()
+
+		results, err := parallel.MapSeq(context.Background(), slices.Values([]int{1, 2, 3}),
+			func(_ context.Context, item int) int { return item * item })
+
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 4, 9}, results)
+	})
+
+	t.Run("maps maps and pairs", func(t *testing.T) {
+		t.Parallel()
+
+		m := map[string]int{"a": 1, "b": 2, "c": 3}
+		expectedError := errors.New("task error")
+
+		byKey, err := parallel.MapMap(context.Background(), m, func(_ context.Context, key string, value int) string {
+			return key + strconv.Itoa(value)
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"a": "a1", "b": "b2", "c": "c3"}, byKey)
+
+		failB := func(_ context.Context, key string, value int) (string, error) {
+			if key == "b" {
+				return "", expectedError
+			}
+
+			return strconv.Itoa(value), nil
+		}
+		byKey, err = parallel.MapErrMap(context.Background(), m, failB, parallel.CollectErrors())
+		require.ErrorIs(t, err, expectedError)
+		require.Equal(t, map[string]string{"a": "1", "c": "3"}, byKey, "Failed keys should be missing")
+
+		pairs := slices.All([]string{"x", "y", "z"})
+		results, err := parallel.MapSeq2(context.Background(), pairs, func(_ context.Context, i int, s string) string {
+			return strconv.Itoa(i) + s
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"0x", "1y", "2z"}, results)
+
+		failSecond := func(_ context.Context, i int, s string) (string, error) {
+			if i == 1 {
+				return "", expectedError
+			}
+
+			return s, nil
+		}
+		results, err = parallel.MapErrSeq2(context.Background(), pairs, failSecond, parallel.CollectErrors())
+		require.ErrorIs(t, err, expectedError)
+		require.Equal(t, []string{"x", "", "z"}, results)
+	})
+
+	t.Run("leaves zero values for failed elements", func(t *testing.T) {
+		t.Parallel()
+
+		expectedError := errors.New("task error")
+		items := []int{1, 2, 3}
+
+		results, err := parallel.MapErr(context.Background(), items, func(_ context.Context, item int) (int, error) {
+			if item == 2 {
+				return 0, expectedError
+			}
+
+			return item * 10, nil
+		}, parallel.CollectErrors())
+
+		require.ErrorIs(t, err, expectedError)
+		require.Equal(t, []int{10, 0, 30}, results)
+	})
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps matching elements in input order", func(t *testing.T) {
+		t.Parallel()
+
+		items := []int{1, 2, 3, 4, 5, 6}
+
+		even, err := parallel.Filter(context.Background(), items, func(_ context.Context, item int) (bool, error) {
+			return item%2 == 0, nil
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, []int{2, 4, 6}, even)
+	})
+
+	t.Run("keeps matching entries of maps and pairs", func(t *testing.T) {
+		t.Parallel()
+
+		m := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+		even := func(_ context.Context, _ string, value int) (bool, error) { return value%2 == 0, nil }
+
+		kept, err := parallel.FilterMap(context.Background(), m, even)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"b": 2, "d": 4}, kept)
+
+		pairs := func(yield func(string, int) bool) {
+			for _, key := range []string{"d", "c", "b", "a"} {
+				if !yield(key, m[key]) {
+					return
+				}
+			}
+		}
+		seq, err := parallel.FilterSeq2(context.Background(), pairs, even)
+		require.NoError(t, err)
+
+		var keys []string
+		for key, value := range seq {
+			require.Equal(t, m[key], value)
+			keys = append(keys, key)
+		}
+		require.Equal(t, []string{"d", "b"}, keys, "Kept pairs should be in input order")
+	})
+}
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	t.Run("folds outputs in input order", func(t *testing.T) {
+		t.Parallel()
+
+		items := []int{1, 2, 3}
+
+		joined, err := parallel.Reduce(context.Background(), items, "",
+			func(_ context.Context, item int) (string, error) { return strconv.Itoa(item), nil },
+			func(acc, value string) string { return acc + value },
+		)
+
+		require.NoError(t, err)
+		require.Equal(t, "123", joined)
+	})
+
+	t.Run("returns the error of a failed call", func(t *testing.T) {
+		t.Parallel()
+
+		expectedError := errors.New("task error")
+
+		sum, err := parallel.Reduce(context.Background(), []int{1, 2}, 100,
+			func(context.Context, int) (int, error) { return 0, expectedError },
+			func(acc, value int) int { return acc + value },
+		)
+
+		require.Equal(t, expectedError, err)
+		require.Zero(t, sum)
+	})
+
+	t.Run("folds the outputs of maps and pairs", func(t *testing.T) {
+		t.Parallel()
+
+		sum, err := parallel.ReduceMap(context.Background(), map[string]int{"a": 1, "b": 2, "c": 3}, 0,
+			func(_ context.Context, _ string, value int) (int, error) { return value * 10, nil },
+			func(acc, value int) int { return acc + value },
+		)
+		require.NoError(t, err)
+		require.Equal(t, 60, sum)
+
+		joined, err := parallel.ReduceSeq2(context.Background(), slices.All([]string{"x", "y", "z"}), "",
+			func(_ context.Context, i int, s string) (string, error) { return strconv.Itoa(i) + s, nil },
+			func(acc, value string) string { return acc + value },
+		)
+		require.NoError(t, err)
+		require.Equal(t, "0x1y2z", joined)
+	})
+}
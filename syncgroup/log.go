@@ -0,0 +1,45 @@
+package syncgroup
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// defaultLogger is the logger set by SetDefaultLogger.
+var defaultLogger atomic.Pointer[slog.Logger] //nolint: gochecknoglobals
+
+// SetDefaultLogger sets the logger used for the default panic and error reporting
+// of every primitive in this module created without a Logger option, including those
+// created before the call. A nil logger restores slog.Default().
+func SetDefaultLogger(logger *slog.Logger) {
+	defaultLogger.Store(logger)
+}
+
+// DefaultLogger returns the logger set by SetDefaultLogger, or slog.Default() if none is set.
+func DefaultLogger() *slog.Logger {
+	if logger := defaultLogger.Load(); logger != nil {
+		return logger
+	}
+
+	return slog.Default()
+}
+
+// LogPanic logs a recovered panic at the error level, with the panic value, the task
+// and the stack as attributes. A nil logger stands for the default logger.
+func LogPanic(logger *slog.Logger, pc any) {
+	if logger == nil {
+		logger = DefaultLogger()
+	}
+
+	pe := NewPanicError(pc)
+	attrs := []any{slog.Any("panic", pe.Value)}
+	if pe.Task != "" {
+		attrs = append(attrs, slog.String("task", pe.Task))
+	}
+	if pe.TaskID != 0 {
+		attrs = append(attrs, slog.Uint64("task_id", pe.TaskID))
+	}
+	attrs = append(attrs, slog.String("stack", string(pe.Stack)))
+
+	logger.Error("panic recovered", attrs...)
+}
@@ -1,7 +1,10 @@
 package syncgroup
 
 import (
-	"log"
+	"log/slog"
+	"time"
+
+	"github.com/safeblock-dev/wr/internal/leak"
 )
 
 // Option represents an option that can be passed when instantiating a WaitGroup to customize it.
@@ -9,15 +12,26 @@ type Option func(wg *WaitGroup)
 
 // PanicHandler allows changing the panic handler function of a WaitGroup.
 // It accepts a function that handles a panic and assigns it to WaitGroup's panicHandler field.
+// The handler receives a *PanicError.
 func PanicHandler(panicHandler func(pc any)) Option {
 	return func(wg *WaitGroup) {
 		wg.panicHandler = panicHandler
 	}
 }
 
-// defaultPanicHandler is the default panic handler that prints the panic information to the log.
-// It uses ANSI escape sequences to colorize the error message in red.
-func defaultPanicHandler(pc any) {
-	const red = "\u001B[31m"
-	log.Printf("[%[1]sERROR%[1]s] %v", red, pc)
+// Logger sets the logger of the default panic handler. By default the logger
+// set by SetDefaultLogger is used.
+func Logger(logger *slog.Logger) Option {
+	return func(wg *WaitGroup) {
+		wg.logger = logger
+	}
+}
+
+// LeakDetector enables leak detection. If Wait has been blocked for longer than timeout,
+// report receives every goroutine still running, with the call site of its Go call,
+// its start time and its current stack. The wait is not interrupted.
+func LeakDetector(timeout time.Duration, report func(Report)) Option {
+	return func(wg *WaitGroup) {
+		wg.leaks = leak.New(timeout, report)
+	}
 }
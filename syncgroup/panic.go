@@ -0,0 +1,49 @@
+package syncgroup
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a panic recovered from a goroutine. It is passed to the panic handlers
+// of all packages in this module, so the stack of the panicking goroutine is not lost.
+type PanicError struct {
+	Value  any    // Value is the value passed to panic.
+	Stack  []byte // Stack is the stack trace of the panicking goroutine.
+	Task   string // Task describes the task that panicked, if known.
+	TaskID uint64 // TaskID is the submission number of the task within its pool or stream, if known.
+}
+
+// NewPanicError wraps a recovered panic value and captures the current stack.
+// It must be called in the deferred function that recovered the panic, so that the stack
+// still contains the panicking frames. A value that already is a *PanicError is returned as is.
+func NewPanicError(value any) *PanicError {
+	if pe, ok := value.(*PanicError); ok {
+		return pe
+	}
+
+	return &PanicError{
+		Value:  value,
+		Stack:  debug.Stack(),
+		Task:   "",
+		TaskID: 0,
+	}
+}
+
+// Error returns the panic value, prefixed with the task if known.
+func (e *PanicError) Error() string {
+	if e.Task != "" {
+		return fmt.Sprintf("panic in %s: %v", e.Task, e.Value)
+	}
+
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
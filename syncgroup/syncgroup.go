@@ -1,21 +1,35 @@
 package syncgroup
 
 import (
+	"log/slog"
 	"sync"
+
+	"github.com/safeblock-dev/wr/internal/leak"
 )
 
+// Report lists the tasks still running after a Wait outlived the timeout of a leak detector.
+type Report = leak.Report
+
+// RunningTask is a task still running when a Report was made.
+type RunningTask = leak.Task
+
 // WaitGroup is a wrapper around sync.WaitGroup with a custom panic handler.
 type WaitGroup struct {
-	panicHandler func(pc any) // panicHandler is a function to handle panics.
+	panicHandler func(pc any)   // panicHandler is a function to handle panics.
+	logger       *slog.Logger   // logger is the logger of the default panic handler.
+	leaks        *leak.Detector // leaks reports goroutines still running when Wait takes too long.
 	wg           sync.WaitGroup
 }
 
 // New creates a new WaitGroup with the provided options.
 func New(options ...Option) *WaitGroup {
 	wg := &WaitGroup{
-		panicHandler: defaultPanicHandler, // Set default panic handler.
-		wg:           sync.WaitGroup{},    // Initialize embedded WaitGroup.
+		panicHandler: nil,
+		logger:       nil,              // Use the default logger.
+		leaks:        nil,              // Leak detection is opt-in.
+		wg:           sync.WaitGroup{}, // Initialize embedded WaitGroup.
 	}
+	wg.panicHandler = wg.logPanic // Set default panic handler.
 
 	// Apply all options.
 	for _, opt := range options {
@@ -26,13 +40,16 @@ func New(options ...Option) *WaitGroup {
 }
 
 // Go runs the given function in a new goroutine and handles panics using the panicHandler.
+// The panic handler receives a *PanicError carrying the stack of the panicking goroutine.
 func (wg *WaitGroup) Go(f func()) {
 	wg.wg.Add(1) // Increment the WaitGroup counter.
+	callSite := wg.leaks.CallSite()
 	go func() {
 		defer wg.wg.Done() // Decrement the WaitGroup counter when done.
+		defer wg.leaks.Start(callSite)()
 		defer func() {
 			if pc := recover(); pc != nil && wg.panicHandler != nil {
-				wg.panicHandler(pc) // Call panic handler on recovery.
+				wg.panicHandler(NewPanicError(pc)) // Call panic handler on recovery.
 			}
 		}()
 
@@ -42,5 +59,11 @@ func (wg *WaitGroup) Go(f func()) {
 
 // Wait waits for all goroutines in the WaitGroup to complete.
 func (wg *WaitGroup) Wait() {
+	defer wg.leaks.Watch()()
 	wg.wg.Wait() // Wait for all goroutines to finish.
 }
+
+// logPanic is the default panic handler that logs the panic.
+func (wg *WaitGroup) logPanic(pc any) {
+	LogPanic(wg.logger, pc)
+}
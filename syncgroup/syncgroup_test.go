@@ -2,9 +2,13 @@ package syncgroup_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"log"
+	"log/slog"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/safeblock-dev/wr/syncgroup"
 	"github.com/stretchr/testify/require"
@@ -87,4 +91,120 @@ func TestPanicHandler(t *testing.T) {
 		// Assert that the custom panic handler was invoked.
 		require.True(t, panicHandled, "Panic should be handled")
 	})
+
+	t.Run("passes panic error with stack", func(t *testing.T) {
+		t.Parallel()
+
+		var recovered any
+		wg := syncgroup.New(syncgroup.PanicHandler(func(pc any) { recovered = pc }))
+
+		wg.Go(func() {
+			panic("test panic")
+		})
+		wg.Wait()
+
+		// Assert that the handler received the panic value and the goroutine's stack.
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, recovered.(error), &pe) //nolint: forcetypeassert
+		require.Equal(t, "test panic", pe.Value)
+		require.Contains(t, string(pe.Stack), "syncgroup_test.TestPanicHandler")
+		require.Equal(t, "panic: test panic", pe.Error())
+	})
+}
+
+// TestPanicError tests the PanicError type.
+func TestPanicError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unwraps error values", func(t *testing.T) {
+		t.Parallel()
+
+		expected := errors.New("test error")
+		err := syncgroup.NewPanicError(expected)
+
+		require.ErrorIs(t, err, expected)
+		require.Nil(t, syncgroup.NewPanicError("test panic").Unwrap())
+	})
+
+	t.Run("does not wrap twice", func(t *testing.T) {
+		t.Parallel()
+
+		err := syncgroup.NewPanicError("test panic")
+		require.Same(t, err, syncgroup.NewPanicError(err))
+	})
+
+	t.Run("describes the task", func(t *testing.T) {
+		t.Parallel()
+
+		err := &syncgroup.PanicError{Value: 42, Stack: nil, Task: "job"}
+		require.Equal(t, "panic in job: 42", err.Error())
+	})
+}
+
+// TestLogger tests structured logging of panics.
+// It is not parallel, as it changes the default logger of all tests.
+func TestLogger(t *testing.T) { //nolint: paralleltest
+	t.Run("logs panics with the logger", func(t *testing.T) {
+		t.Parallel()
+
+		var logBuffer bytes.Buffer
+		wg := syncgroup.New(syncgroup.Logger(slog.New(slog.NewJSONHandler(&logBuffer, nil))))
+
+		wg.Go(func() {
+			panic("test panic")
+		})
+		wg.Wait()
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(logBuffer.Bytes(), &record))
+		require.Equal(t, "ERROR", record["level"])
+		require.Equal(t, "panic recovered", record["msg"])
+		require.Equal(t, "test panic", record["panic"])
+		require.Contains(t, record["stack"], "syncgroup_test.TestLogger")
+	})
+
+	t.Run("logs panics with the default logger", func(t *testing.T) { //nolint: paralleltest
+		var logBuffer bytes.Buffer
+		syncgroup.SetDefaultLogger(slog.New(slog.NewJSONHandler(&logBuffer, nil)))
+		defer syncgroup.SetDefaultLogger(nil)
+
+		wg := syncgroup.New()
+		wg.Go(func() {
+			panic("test panic")
+		})
+		wg.Wait()
+
+		require.Contains(t, logBuffer.String(), `"panic":"test panic"`)
+	})
+}
+
+// TestLeakDetector tests the reporting of goroutines blocking Wait.
+func TestLeakDetector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports goroutines still running", func(t *testing.T) {
+		t.Parallel()
+
+		reports := make(chan syncgroup.Report, 1)
+		wg := syncgroup.New(syncgroup.LeakDetector(10*time.Millisecond, func(r syncgroup.Report) {
+			reports <- r
+		}))
+		release := make(chan struct{})
+
+		wg.Go(func() {})
+		wg.Go(func() { <-release })
+		waited := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(waited)
+		}()
+
+		report := <-reports
+		close(release)
+		<-waited // The wait continues after the report.
+
+		require.Len(t, report.Tasks, 1)
+		require.Contains(t, report.Tasks[0].CallSite, "syncgroup_test.go:")
+		require.Contains(t, string(report.Tasks[0].Stack), "syncgroup_test.TestLeakDetector")
+	})
 }
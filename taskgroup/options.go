@@ -0,0 +1,20 @@
+package taskgroup
+
+import (
+	"time"
+
+	"github.com/safeblock-dev/wr/internal/leak"
+	"github.com/safeblock-dev/wr/syncgroup"
+)
+
+// Option represents an option that can be passed when instantiating a TaskGroup to customize it.
+type Option func(g *TaskGroup)
+
+// LeakDetector enables leak detection. If Run has been waiting for longer than timeout
+// for the interrupted tasks to exit, report receives every task still running, with the
+// call site where it was added, its start time and its current stack. Run is not interrupted.
+func LeakDetector(timeout time.Duration, report func(syncgroup.Report)) Option {
+	return func(g *TaskGroup) {
+		g.leaks = leak.New(timeout, report)
+	}
+}
@@ -3,7 +3,7 @@ package taskgroup
 import (
 	"context"
 
-	"github.com/safeblock-dev/werr"
+	"github.com/safeblock-dev/wr/internal/leak"
 	"github.com/safeblock-dev/wr/syncgroup"
 )
 
@@ -12,6 +12,7 @@ import (
 // A TaskGroup with no tasks is a valid, empty group.
 type TaskGroup struct {
 	actors []actor
+	leaks  *leak.Detector
 }
 
 // actor represents a task with an execute function and an interrupt function.
@@ -19,6 +20,7 @@ type TaskGroup struct {
 type actor struct {
 	execute   ExecuteFn
 	interrupt InterruptFn
+	site      string
 }
 
 type ExecuteFn func() error
@@ -26,11 +28,19 @@ type ExecuteCtxFn func(ctx context.Context) error
 type InterruptFn func(err error)
 type InterruptCtxFn func(ctx context.Context, err error)
 
-// New creates a new, empty TaskGroup.
-func New() *TaskGroup {
-	return &TaskGroup{
+// New creates a new, empty TaskGroup with the provided options.
+func New(options ...Option) *TaskGroup {
+	g := &TaskGroup{
 		actors: nil,
+		leaks:  nil,
 	}
+
+	// Apply all options.
+	for _, opt := range options {
+		opt(g)
+	}
+
+	return g
 }
 
 // Add appends a new task (actor) to the TaskGroup.
@@ -42,7 +52,7 @@ func (g *TaskGroup) Add(execute ExecuteFn, interrupt InterruptFn) {
 		panic("execute and interrupt functions must not be nil")
 	}
 
-	g.actors = append(g.actors, actor{execute, interrupt})
+	g.actors = append(g.actors, actor{execute, interrupt, g.leaks.CallSite()})
 }
 
 // AddContext adds a task to the TaskGroup that operates within a given context.
@@ -68,6 +78,7 @@ func (g *TaskGroup) AddContext(execute ExecuteCtxFn, interrupt InterruptCtxFn) {
 			cancel()
 			interrupt(ctx, err)
 		},
+		site: g.leaks.CallSite(),
 	})
 }
 
@@ -85,11 +96,12 @@ func (g *TaskGroup) Run() error {
 
 	// Use syncgroup to manage task goroutines and handle panics.
 	wg := syncgroup.New(syncgroup.PanicHandler(func(pc any) {
-		errors <- werr.PanicToError(pc)
+		errors <- syncgroup.NewPanicError(pc)
 	}))
 
 	for _, a := range g.actors {
 		wg.Go(func() {
+			defer g.leaks.Start(a.site)()
 			errors <- a.execute()
 		})
 	}
@@ -103,7 +115,9 @@ func (g *TaskGroup) Run() error {
 	}
 
 	// Wait for all tasks to complete before returning.
+	stopWatch := g.leaks.Watch()
 	wg.Wait()
+	stopWatch()
 
 	return err
 }
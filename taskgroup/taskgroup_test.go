@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/safeblock-dev/wr/syncgroup"
 	"github.com/safeblock-dev/wr/taskgroup"
 	"github.com/stretchr/testify/require"
 )
@@ -162,6 +164,11 @@ func TestTaskGroup_Run(t *testing.T) {
 		err := tg.Run()
 		require.Error(t, err)
 		require.Contains(t, err.Error(), panicValue)
+
+		var pe *syncgroup.PanicError
+		require.ErrorAs(t, err, &pe)
+		require.Equal(t, panicValue, pe.Value)
+		require.NotEmpty(t, pe.Stack)
 	})
 
 	t.Run("should allow restarting group after Run", func(t *testing.T) {
@@ -201,3 +208,32 @@ func TestTaskGroup_Size(t *testing.T) {
 
 	require.Equal(t, 1, tg.Size())
 }
+
+func TestTaskGroup_LeakDetector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reports tasks ignoring the interrupt", func(t *testing.T) {
+		t.Parallel()
+
+		reports := make(chan syncgroup.Report, 1)
+		tg := taskgroup.New(taskgroup.LeakDetector(10*time.Millisecond, func(r syncgroup.Report) {
+			reports <- r
+		}))
+		release := make(chan struct{})
+
+		tg.Add(func() error { return nil }, taskgroup.SkipInterrupt())
+		tg.Add(func() error { <-release; return nil }, taskgroup.SkipInterrupt())
+		waited := make(chan struct{})
+		go func() {
+			_ = tg.Run()
+			close(waited)
+		}()
+
+		report := <-reports
+		close(release)
+		<-waited // Run continues after the report.
+
+		require.Len(t, report.Tasks, 1)
+		require.Contains(t, report.Tasks[0].CallSite, "taskgroup_test.go:")
+	})
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+)
+
+// RecordedSpan is a span recorded by a Recorder.
+type RecordedSpan struct {
+	ID       int    // ID identifies the span, starting at 1.
+	ParentID int    // ParentID is the ID of the parent span, zero for a root span.
+	Name     string // Name is the name of the span.
+	Err      error  // Err is the last error recorded on the span.
+	Ended    bool   // Ended indicates if the span has ended.
+}
+
+// Recorder is a Tracer that keeps spans in memory, intended for tests.
+type Recorder struct {
+	mu    sync.Mutex      // mu protects spans.
+	spans []*RecordedSpan // spans stores the started spans in order.
+}
+
+// spanKey is the context key of the span started by a Recorder.
+type spanKey struct{}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{} //nolint: exhaustruct
+}
+
+// Start records a new span as a child of the span carried by ctx.
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	span := &RecordedSpan{ID: len(r.spans) + 1, Name: name} //nolint: exhaustruct
+	if parent, ok := ctx.Value(spanKey{}).(*RecordedSpan); ok {
+		span.ParentID = parent.ID
+	}
+	r.spans = append(r.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), recordedSpan{recorder: r, span: span}
+}
+
+// Spans returns a snapshot of the recorded spans in the order they were started.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]RecordedSpan, len(r.spans))
+	for i, span := range r.spans {
+		spans[i] = *span
+	}
+
+	return spans
+}
+
+// recordedSpan is the Span handed out by a Recorder.
+type recordedSpan struct {
+	recorder *Recorder     // recorder owns the span.
+	span     *RecordedSpan // span is the recorded data.
+}
+
+// RecordError records err on the span.
+func (s recordedSpan) RecordError(err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.span.Err = err
+}
+
+// End marks the span as ended.
+func (s recordedSpan) End() {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+
+	s.span.Ended = true
+}
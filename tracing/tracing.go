@@ -0,0 +1,65 @@
+// Package tracing defines the hooks used by gopool and gostream to trace tasks
+// across the boundary between the submitting goroutine and the worker running the task.
+package tracing
+
+import (
+	"context"
+)
+
+// Tracer starts spans. Pools and streams start a span when a task is submitted and
+// a child span when it is executed, so traces propagate from submitter into the task.
+// Adapters for tracing libraries such as OpenTelemetry implement this interface.
+type Tracer interface {
+	// Start starts a span named name as a child of the span carried by ctx,
+	// and returns a context carrying the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a traced operation.
+type Span interface {
+	// RecordError records an error returned by the operation. A panic is recorded
+	// as a *syncgroup.PanicError.
+	RecordError(err error)
+	// End ends the span.
+	End()
+}
+
+// Noop is the default Tracer. It starts no spans and returns the context unchanged.
+var Noop Tracer = noopTracer{} //nolint: gochecknoglobals
+
+// noopTracer is a Tracer that does nothing.
+type noopTracer struct{}
+
+// Start returns ctx and a span that does nothing.
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// noopSpan is a Span that does nothing.
+type noopSpan struct{}
+
+func (noopSpan) RecordError(error) {}
+
+func (noopSpan) End() {}
+
+// Link returns a context that is cancelled with ctx and carries the values of spanCtx,
+// falling back to the values of ctx. It hands the span started for a task, along with
+// the submitter's values, to a task running under the pool's context.
+func Link(ctx, spanCtx context.Context) context.Context {
+	return linkedCtx{Context: ctx, values: spanCtx}
+}
+
+// linkedCtx is a context whose values are looked up in values first.
+type linkedCtx struct {
+	context.Context                 // Context provides the deadline and cancellation.
+	values          context.Context // values provides the span and the submitter's values.
+}
+
+// Value returns the value associated with key in values, or in the embedded context.
+func (c linkedCtx) Value(key any) any {
+	if value := c.values.Value(key); value != nil {
+		return value
+	}
+
+	return c.Context.Value(key)
+}
@@ -0,0 +1,62 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/safeblock-dev/wr/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+// ctxKey is a context key used in tests.
+type ctxKey struct{}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records span hierarchy", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := tracing.NewRecorder()
+		expectedError := errors.New("test error")
+
+		ctx, parent := recorder.Start(context.Background(), "parent")
+		_, child := recorder.Start(ctx, "child")
+		child.RecordError(expectedError)
+		child.End()
+		parent.End()
+
+		require.Equal(t, []tracing.RecordedSpan{
+			{ID: 1, ParentID: 0, Name: "parent", Err: nil, Ended: true},
+			{ID: 2, ParentID: 1, Name: "child", Err: expectedError, Ended: true},
+		}, recorder.Spans())
+	})
+}
+
+func TestNoop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	spanCtx, span := tracing.Noop.Start(ctx, "noop")
+	span.RecordError(errors.New("test error"))
+	span.End()
+
+	require.Equal(t, ctx, spanCtx, "Noop should not change the context")
+}
+
+func TestLink(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), ctxKey{}, "pool"), time.Hour)
+	spanCtx := context.WithValue(context.Background(), ctxKey{}, "submitter")
+	linked := tracing.Link(ctx, spanCtx)
+
+	require.Equal(t, "submitter", linked.Value(ctxKey{}), "Values of spanCtx should take precedence")
+	_, ok := linked.Deadline()
+	require.True(t, ok, "Deadline should come from ctx")
+
+	cancel()
+	require.ErrorIs(t, linked.Err(), context.Canceled, "Cancellation should come from ctx")
+}